@@ -5,22 +5,27 @@ import (
 	"hermes/app/types"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	apigw_types "github.com/aws/aws-sdk-go-v2/service/apigatewayv2/types"
 )
 
 var _ types.ResourceStatus = APIGatewayStatus{}
 
 type APIGatewayStatus struct {
 	InstanceExists bool   `json:"exists"`
+	ApiId          string `json:"api_id"`
 	Endpoint       string `json:"endpoint"`
 	Protocol       string `json:"protocol"`
 }
 
 func (a APIGatewayStatus) IsResourceStatus() {}
 
-func (a APIGatewayStatus) IsHealthy() bool {
-	return true
+func (a APIGatewayStatus) GetHealth() types.Health {
+	if !a.InstanceExists {
+		return types.Unhealthy("not found")
+	}
+
+	return types.Healthy()
 }
 
 func (a APIGatewayStatus) Exists() bool {
@@ -31,50 +36,50 @@ func (a APIGatewayStatus) GetStatusString() string {
 	return "active"
 }
 
-func GetAPIGatewayStatus(client *apigatewayv2.Client, apiName string) (APIGatewayStatus, error) {
-	// TODO: pagination
-	apis, err := client.GetApis(context.TODO(), &apigatewayv2.GetApisInput{})
+// findApi pages through every HTTP/WebSocket API in the account and returns
+// the first one whose ID or name matches the given identifier
+func findApi(client *apigatewayv2.Client, apiIdentifier string) (apigw_types.Api, bool, error) {
+	input := &apigatewayv2.GetApisInput{}
 
-	if err != nil {
-		return APIGatewayStatus{}, err
-	}
+	for {
+		resp, err := client.GetApis(context.TODO(), input)
+		if err != nil {
+			return apigw_types.Api{}, false, err
+		}
 
-	var apiId string
-	for _, api := range apis.Items {
-		if *api.Name == apiName {
-			apiId = *api.ApiId
-			break
+		for _, api := range resp.Items {
+			if aws.ToString(api.ApiId) == apiIdentifier || aws.ToString(api.Name) == apiIdentifier {
+				return api, true, nil
+			}
 		}
-	}
 
-	if apiId == "" {
-		return APIGatewayStatus{
-			InstanceExists: false,
-		}, nil
+		if resp.NextToken == nil {
+			return apigw_types.Api{}, false, nil
+		}
+
+		input.NextToken = resp.NextToken
 	}
+}
 
-	resp, err := client.GetApi(context.TODO(), &apigatewayv2.GetApiInput{
-		ApiId: aws.String(apiId),
-	})
+func GetAPIGatewayStatus(client *apigatewayv2.Client, apiIdentifier string) (APIGatewayStatus, error) {
+	api, found, err := findApi(client, apiIdentifier)
 
 	if err != nil {
 		return APIGatewayStatus{}, err
 	}
 
+	if !found {
+		return APIGatewayStatus{
+			InstanceExists: false,
+		}, nil
+	}
+
 	// TODO: maybe more info
 
 	return APIGatewayStatus{
 		InstanceExists: true,
-		Endpoint:       *resp.ApiEndpoint,
-		Protocol:       string(resp.ProtocolType),
+		ApiId:          aws.ToString(api.ApiId),
+		Endpoint:       aws.ToString(api.ApiEndpoint),
+		Protocol:       string(api.ProtocolType),
 	}, nil
 }
-
-func GetAPIGatewayClient() (*apigatewayv2.Client, error) {
-	cfg, err := config.LoadDefaultConfig(context.TODO())
-	if err != nil {
-		return nil, err
-	}
-
-	return apigatewayv2.NewFromConfig(cfg), nil
-}
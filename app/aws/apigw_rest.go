@@ -0,0 +1,125 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"hermes/app/types"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/apigateway"
+	apigw_rest_types "github.com/aws/aws-sdk-go-v2/service/apigateway/types"
+)
+
+var _ types.ResourceStatus = APIGatewayRestStatus{}
+
+type APIGatewayRestStatus struct {
+	InstanceExists bool                  `json:"exists"`
+	ApiId          string                `json:"api_id"`
+	Endpoint       string                `json:"endpoint"`
+	EndpointTypes  []string              `json:"endpoint_types"`
+	Stages         []APIGatewayRestStage `json:"stages"`
+}
+
+func (a APIGatewayRestStatus) IsResourceStatus() {}
+
+func (a APIGatewayRestStatus) GetHealth() types.Health {
+	if !a.InstanceExists {
+		return types.Unhealthy("not found")
+	}
+
+	if len(a.Stages) == 0 {
+		return types.Unhealthy("no deployed stages")
+	}
+
+	return types.Healthy()
+}
+
+func (a APIGatewayRestStatus) Exists() bool {
+	return a.InstanceExists
+}
+
+func (a APIGatewayRestStatus) GetStatusString() string {
+	if len(a.Stages) == 0 {
+		return "undeployed"
+	}
+
+	return "active"
+}
+
+type APIGatewayRestStage struct {
+	Name         string    `json:"name"`
+	DeploymentId string    `json:"deployment_id"`
+	LastUpdated  time.Time `json:"last_updated"`
+}
+
+// findRestApi pages through every REST API in the account and returns the
+// first one whose ID or name matches the given identifier
+func findRestApi(client *apigateway.Client, apiIdentifier string) (apigw_rest_types.RestApi, bool, error) {
+	paginator := apigateway.NewGetRestApisPaginator(client, &apigateway.GetRestApisInput{})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return apigw_rest_types.RestApi{}, false, err
+		}
+
+		for _, api := range page.Items {
+			if aws.ToString(api.Id) == apiIdentifier || aws.ToString(api.Name) == apiIdentifier {
+				return api, true, nil
+			}
+		}
+	}
+
+	return apigw_rest_types.RestApi{}, false, nil
+}
+
+func GetAPIGatewayRestStatus(client *apigateway.Client, apiIdentifier string) (APIGatewayRestStatus, error) {
+	api, found, err := findRestApi(client, apiIdentifier)
+
+	if err != nil {
+		return APIGatewayRestStatus{}, err
+	}
+
+	if !found {
+		return APIGatewayRestStatus{
+			InstanceExists: false,
+		}, nil
+	}
+
+	apiId := aws.ToString(api.Id)
+
+	stagesResp, err := client.GetStages(context.TODO(), &apigateway.GetStagesInput{
+		RestApiId: aws.String(apiId),
+	})
+
+	if err != nil {
+		return APIGatewayRestStatus{}, err
+	}
+
+	stages := []APIGatewayRestStage{}
+	for _, stage := range stagesResp.Item {
+		stages = append(stages,
+			APIGatewayRestStage{
+				Name:         aws.ToString(stage.StageName),
+				DeploymentId: aws.ToString(stage.DeploymentId),
+				LastUpdated:  aws.ToTime(stage.LastUpdatedDate),
+			},
+		)
+	}
+
+	endpointTypes := []string{}
+	if api.EndpointConfiguration != nil {
+		for _, endpointType := range api.EndpointConfiguration.Types {
+			endpointTypes = append(endpointTypes, string(endpointType))
+		}
+	}
+
+	return APIGatewayRestStatus{
+		InstanceExists: true,
+		ApiId:          apiId,
+		Endpoint:       fmt.Sprintf("https://%s.execute-api.%s.amazonaws.com", apiId, client.Options().Region),
+		EndpointTypes:  endpointTypes,
+		Stages:         stages,
+	}, nil
+}
@@ -0,0 +1,119 @@
+package aws
+
+import (
+	"context"
+	"hermes/app/types"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/apigateway"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+const roleSessionName = "hermes"
+
+// ServiceClients holds a client for each aws service hermes queries, all
+// sharing one region and set of credentials
+type ServiceClients struct {
+	ECS            *ecs.Client
+	RDS            *rds.Client
+	CloudWatch     *cloudwatch.Client
+	ELB            *elasticloadbalancingv2.Client
+	APIGateway     *apigatewayv2.Client
+	APIGatewayRest *apigateway.Client
+	Lambda         *lambda.Client
+	Tagging        *resourcegroupstaggingapi.Client
+	CloudFormation *cloudformation.Client
+}
+
+// ClientCache builds service clients on first use and caches them per
+// target, so each (profile, region, role) combination shares a single
+// credential cache
+type ClientCache struct {
+	mu      sync.Mutex
+	clients map[types.AWSTarget]ServiceClients
+}
+
+func NewClientCache() *ClientCache {
+	return &ClientCache{
+		clients: map[types.AWSTarget]ServiceClients{},
+	}
+}
+
+func (c *ClientCache) Get(target types.AWSTarget) (ServiceClients, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clients, found := c.clients[target]
+	if found {
+		return clients, nil
+	}
+
+	cfg, err := loadConfig(target)
+	if err != nil {
+		return ServiceClients{}, err
+	}
+
+	clients = ServiceClients{
+		ECS:            ecs.NewFromConfig(cfg),
+		RDS:            rds.NewFromConfig(cfg),
+		CloudWatch:     cloudwatch.NewFromConfig(cfg),
+		ELB:            elasticloadbalancingv2.NewFromConfig(cfg),
+		APIGateway:     apigatewayv2.NewFromConfig(cfg),
+		APIGatewayRest: apigateway.NewFromConfig(cfg),
+		Lambda:         lambda.NewFromConfig(cfg),
+		Tagging:        resourcegroupstaggingapi.NewFromConfig(cfg),
+		CloudFormation: cloudformation.NewFromConfig(cfg),
+	}
+
+	c.clients[target] = clients
+
+	return clients, nil
+}
+
+// loadConfig starts from the default config, narrowed to the target's region
+// and profile. When a role is given it is assumed on top of those
+// credentials, and the resulting session is refreshed before it expires
+func loadConfig(target types.AWSTarget) (aws.Config, error) {
+	options := []func(*config.LoadOptions) error{}
+
+	if target.Region != "" {
+		options = append(options, config.WithRegion(target.Region))
+	}
+
+	if target.Profile != "" {
+		options = append(options, config.WithSharedConfigProfile(target.Profile))
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.TODO(), options...)
+	if err != nil {
+		return aws.Config{}, err
+	}
+
+	if target.RoleARN != "" {
+		provider := stscreds.NewAssumeRoleProvider(
+			sts.NewFromConfig(cfg),
+			target.RoleARN,
+			func(o *stscreds.AssumeRoleOptions) {
+				o.RoleSessionName = roleSessionName
+				if target.ExternalID != "" {
+					o.ExternalID = aws.String(target.ExternalID)
+				}
+			},
+		)
+
+		cfg.Credentials = aws.NewCredentialsCache(provider)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,160 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"hermes/app/types"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/smithy-go"
+)
+
+var _ types.ResourceStatus = CloudFormationStatus{}
+
+type CloudFormationStatus struct {
+	InstanceExists  bool      `json:"exists"`
+	Status          string    `json:"status"`
+	StatusReason    string    `json:"status_reason,omitempty"`
+	DriftStatus     string    `json:"drift_status"`
+	CreatedTime     time.Time `json:"created_time"`
+	LastUpdatedTime time.Time `json:"last_updated_time"`
+}
+
+func (c CloudFormationStatus) IsResourceStatus() {}
+
+// GetHealth treats a stack as healthy unless its last operation failed or
+// was rolled back, so a stack mid-update stays healthy. An update rollback
+// that completed (UPDATE_ROLLBACK_COMPLETE) leaves the stack running, but on
+// a template that isn't the one that was deployed, so is only degraded
+func (c CloudFormationStatus) GetHealth() types.Health {
+	switch {
+	case !c.InstanceExists:
+		return types.Unhealthy("not found")
+	case c.Status == "UPDATE_ROLLBACK_COMPLETE":
+		return types.Degraded(stateReason(c.Status, c.StatusReason))
+	case strings.Contains(c.Status, "ROLLBACK"),
+		strings.Contains(c.Status, "FAILED"),
+		strings.HasPrefix(c.Status, "DELETE"):
+		return types.Unhealthy(stateReason(c.Status, c.StatusReason))
+	default:
+		return types.Healthy()
+	}
+}
+
+func (c CloudFormationStatus) Exists() bool {
+	return c.InstanceExists
+}
+
+func (c CloudFormationStatus) GetStatusString() string {
+	return c.Status
+}
+
+// isStackNotFound reports whether err is the validation error cloudformation
+// returns for a stack that doesn't exist, which has no dedicated type
+func isStackNotFound(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) &&
+		apiErr.ErrorCode() == "ValidationError" &&
+		strings.Contains(apiErr.ErrorMessage(), "does not exist")
+}
+
+func GetCloudFormationStatus(client *cloudformation.Client, stackName string) (CloudFormationStatus, error) {
+	resp, err := client.DescribeStacks(context.TODO(), &cloudformation.DescribeStacksInput{
+		StackName: aws.String(stackName),
+	})
+
+	if err != nil {
+		if isStackNotFound(err) {
+			return CloudFormationStatus{
+				InstanceExists: false,
+			}, nil
+		}
+
+		return CloudFormationStatus{}, err
+	}
+
+	if len(resp.Stacks) == 0 {
+		return CloudFormationStatus{
+			InstanceExists: false,
+		}, nil
+	}
+
+	stack := resp.Stacks[0]
+
+	status := CloudFormationStatus{
+		InstanceExists:  true,
+		Status:          string(stack.StackStatus),
+		StatusReason:    aws.ToString(stack.StackStatusReason),
+		CreatedTime:     aws.ToTime(stack.CreationTime),
+		LastUpdatedTime: aws.ToTime(stack.LastUpdatedTime),
+	}
+
+	if stack.DriftInformation != nil {
+		status.DriftStatus = string(stack.DriftInformation.StackDriftStatus)
+	}
+
+	return status, nil
+}
+
+// the resource type each supported cloudformation type maps to
+var stackResourceTypes = map[string]types.ResourceType{
+	"AWS::ECS::Cluster":                         types.ECSResource,
+	"AWS::RDS::DBInstance":                      types.RDSResource,
+	"AWS::ElasticLoadBalancingV2::LoadBalancer": types.ELBResource,
+	"AWS::ApiGatewayV2::Api":                    types.APIGatewayResource,
+	"AWS::ApiGateway::RestApi":                  types.APIGatewayRestResource,
+	"AWS::Lambda::Function":                     types.LambdaResource,
+	"AWS::CloudFormation::Stack":                types.CloudFormationResource,
+}
+
+// ImportStack returns a definition for the stack itself followed by each of
+// its supported resources, named after their logical ids
+func ImportStack(client *cloudformation.Client, stackName string) ([]types.ResourceDefinition, error) {
+	resources := []types.ResourceDefinition{
+		{
+			Name:       stackName,
+			Identifier: stackName,
+			Type:       types.CloudFormationResource,
+		},
+	}
+
+	paginator := cloudformation.NewListStackResourcesPaginator(client, &cloudformation.ListStackResourcesInput{
+		StackName: aws.String(stackName),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+
+		for _, summary := range page.StackResourceSummaries {
+			resourceType, found := stackResourceTypes[aws.ToString(summary.ResourceType)]
+			identifier := aws.ToString(summary.PhysicalResourceId)
+			if !found || identifier == "" {
+				continue
+			}
+
+			// load balancers are identified by their arn
+			if resourceType == types.ELBResource {
+				name, ok := identifierFromARN(resourceType, identifier)
+				if !ok {
+					continue
+				}
+
+				identifier = name
+			}
+
+			resources = append(resources, types.ResourceDefinition{
+				Name:       aws.ToString(summary.LogicalResourceId),
+				Identifier: identifier,
+				Type:       resourceType,
+			})
+		}
+	}
+
+	return resources, nil
+}
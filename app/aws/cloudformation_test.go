@@ -0,0 +1,168 @@
+package aws
+
+import (
+	"fmt"
+	"hermes/app/types"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+)
+
+const stackNotFoundResponse = `<ErrorResponse xmlns="http://cloudformation.amazonaws.com/doc/2010-05-15/">
+  <Error>
+    <Type>Sender</Type>
+    <Code>ValidationError</Code>
+    <Message>Stack with id %s does not exist</Message>
+  </Error>
+  <RequestId>request</RequestId>
+</ErrorResponse>`
+
+const describeStacksResponse = `<DescribeStacksResponse xmlns="http://cloudformation.amazonaws.com/doc/2010-05-15/">
+  <DescribeStacksResult>
+    <Stacks>
+      <member>
+        <StackName>api</StackName>
+        <StackStatus>UPDATE_ROLLBACK_COMPLETE</StackStatus>
+        <StackStatusReason>Resource update cancelled</StackStatusReason>
+        <CreationTime>2024-01-01T00:00:00Z</CreationTime>
+        <DriftInformation>
+          <StackDriftStatus>IN_SYNC</StackDriftStatus>
+        </DriftInformation>
+      </member>
+    </Stacks>
+  </DescribeStacksResult>
+</DescribeStacksResponse>`
+
+// the stack's resources, served two per page
+var stackResourceSummaries = []struct {
+	logicalID    string
+	physicalID   string
+	resourceType string
+}{
+	{"Cluster", "api-cluster", "AWS::ECS::Cluster"},
+	{"Bucket", "api-assets", "AWS::S3::Bucket"},
+	{"Database", "api-db", "AWS::RDS::DBInstance"},
+	{"LoadBalancer", "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/api-alb/50dc6c495c0c9188", "AWS::ElasticLoadBalancingV2::LoadBalancer"},
+	{"GatewayLoadBalancer", "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/gwy/api-gwlb/60dc6c495c0c9188", "AWS::ElasticLoadBalancingV2::LoadBalancer"},
+	{"Handler", "api-handler", "AWS::Lambda::Function"},
+	{"PendingFunction", "", "AWS::Lambda::Function"},
+}
+
+// newFakeCloudFormation serves DescribeStacks and ListStackResources for a
+// single stack named api, returning a client pointed at it
+func newFakeCloudFormation(t *testing.T) *cloudformation.Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/xml")
+
+		stackName := r.Form.Get("StackName")
+		if stackName != "api" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, stackNotFoundResponse, stackName)
+			return
+		}
+
+		switch r.Form.Get("Action") {
+		case "DescribeStacks":
+			fmt.Fprint(w, describeStacksResponse)
+		case "ListStackResources":
+			start := 0
+			fmt.Sscanf(r.Form.Get("NextToken"), "page-%d", &start)
+			end := min(start+2, len(stackResourceSummaries))
+
+			fmt.Fprint(w, `<ListStackResourcesResponse xmlns="http://cloudformation.amazonaws.com/doc/2010-05-15/">`)
+			fmt.Fprint(w, `<ListStackResourcesResult><StackResourceSummaries>`)
+			for _, summary := range stackResourceSummaries[start:end] {
+				fmt.Fprintf(w,
+					`<member><LogicalResourceId>%s</LogicalResourceId><PhysicalResourceId>%s</PhysicalResourceId>`+
+						`<ResourceType>%s</ResourceType><ResourceStatus>CREATE_COMPLETE</ResourceStatus>`+
+						`<LastUpdatedTimestamp>2024-01-01T00:00:00Z</LastUpdatedTimestamp></member>`,
+					summary.logicalID, summary.physicalID, summary.resourceType,
+				)
+			}
+			fmt.Fprint(w, `</StackResourceSummaries>`)
+			if end < len(stackResourceSummaries) {
+				fmt.Fprintf(w, `<NextToken>page-%d</NextToken>`, end)
+			}
+			fmt.Fprint(w, `</ListStackResourcesResult></ListStackResourcesResponse>`)
+		default:
+			http.Error(w, "unexpected action", http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return cloudformation.New(cloudformation.Options{
+		Region:       "us-east-1",
+		Credentials:  credentials.NewStaticCredentialsProvider("key", "secret", ""),
+		BaseEndpoint: aws.String(server.URL),
+	})
+}
+
+func TestImportStack(t *testing.T) {
+	client := newFakeCloudFormation(t)
+
+	resources, err := ImportStack(client, "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []types.ResourceDefinition{
+		{Name: "api", Identifier: "api", Type: types.CloudFormationResource},
+		{Name: "Cluster", Identifier: "api-cluster", Type: types.ECSResource},
+		{Name: "Database", Identifier: "api-db", Type: types.RDSResource},
+		{Name: "LoadBalancer", Identifier: "api-alb", Type: types.ELBResource},
+		{Name: "Handler", Identifier: "api-handler", Type: types.LambdaResource},
+	}
+
+	equal := slices.EqualFunc(resources, want, func(a, b types.ResourceDefinition) bool {
+		return a.Name == b.Name && a.Identifier == b.Identifier && a.Type == b.Type
+	})
+	if !equal {
+		t.Errorf("got %+v, want %+v", resources, want)
+	}
+}
+
+func TestImportStackNotFound(t *testing.T) {
+	_, err := ImportStack(newFakeCloudFormation(t), "missing")
+	if err == nil {
+		t.Error("expected an error importing a stack that doesn't exist")
+	}
+}
+
+func TestGetCloudFormationStatus(t *testing.T) {
+	client := newFakeCloudFormation(t)
+
+	status, err := GetCloudFormationStatus(client, "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !status.InstanceExists || status.Status != "UPDATE_ROLLBACK_COMPLETE" || status.DriftStatus != "IN_SYNC" {
+		t.Errorf("got %+v", status)
+	}
+
+	if health := status.GetHealth(); health.State != types.HealthDegraded {
+		t.Errorf("got health %s, want degraded", health.State)
+	}
+
+	status, err = GetCloudFormationStatus(client, "missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status.InstanceExists {
+		t.Error("expected the stack not to exist")
+	}
+}
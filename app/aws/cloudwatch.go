@@ -0,0 +1,49 @@
+package aws
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cloudwatch_types "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// getLatestMetricMinimum returns the minimum of the most recent datapoint
+// reported for the given metric over the last fifteen minutes. The boolean is
+// false when CloudWatch has no datapoints in that window
+func getLatestMetricMinimum(
+	client *cloudwatch.Client,
+	namespace string,
+	metricName string,
+	dimensions []cloudwatch_types.Dimension,
+) (float64, bool, error) {
+	now := time.Now()
+
+	resp, err := client.GetMetricStatistics(context.TODO(), &cloudwatch.GetMetricStatisticsInput{
+		Namespace:  aws.String(namespace),
+		MetricName: aws.String(metricName),
+		Dimensions: dimensions,
+		StartTime:  aws.Time(now.Add(-15 * time.Minute)),
+		EndTime:    aws.Time(now),
+		Period:     aws.Int32(300),
+		Statistics: []cloudwatch_types.Statistic{cloudwatch_types.StatisticMinimum},
+	})
+
+	if err != nil {
+		return 0, false, err
+	}
+
+	var latest *cloudwatch_types.Datapoint
+	for _, datapoint := range resp.Datapoints {
+		if latest == nil || datapoint.Timestamp.After(*latest.Timestamp) {
+			latest = &datapoint
+		}
+	}
+
+	if latest == nil || latest.Minimum == nil {
+		return 0, false, nil
+	}
+
+	return *latest.Minimum, true, nil
+}
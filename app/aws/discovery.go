@@ -0,0 +1,118 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"hermes/app/types"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	tagging_types "github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+)
+
+// the tagging api resource type filter for each discoverable resource type
+var taggingResourceTypes = map[types.ResourceType]string{
+	types.ECSResource:    "ecs:cluster",
+	types.RDSResource:    "rds:db",
+	types.ELBResource:    "elasticloadbalancing:loadbalancer",
+	types.LambdaResource: "lambda:function",
+}
+
+// IsDiscoverable reports whether resources of the given type can be found
+// by their tags
+func IsDiscoverable(resourceType types.ResourceType) bool {
+	_, found := taggingResourceTypes[resourceType]
+	return found
+}
+
+// identifierFromARN converts an arn into the identifier the resource type's
+// status lookup expects, reporting false for arns of other resource kinds
+// that share a tagging filter, such as classic or gateway load balancers
+func identifierFromARN(resourceType types.ResourceType, resourceARN string) (string, bool) {
+	parsed, err := arn.Parse(resourceARN)
+	if err != nil {
+		return "", false
+	}
+
+	switch resourceType {
+	case types.ECSResource:
+		// cluster/name
+		name, found := strings.CutPrefix(parsed.Resource, "cluster/")
+		return name, found
+	case types.RDSResource:
+		// db:name
+		name, found := strings.CutPrefix(parsed.Resource, "db:")
+		return name, found
+	case types.ELBResource:
+		// loadbalancer/app/name/id or loadbalancer/net/name/id
+		parts := strings.Split(parsed.Resource, "/")
+		if len(parts) != 4 || (parts[1] != "app" && parts[1] != "net") {
+			return "", false
+		}
+
+		return parts[2], true
+	case types.LambdaResource:
+		// function:name, possibly followed by a version or alias
+		parts := strings.Split(parsed.Resource, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] != "function" {
+			return "", false
+		}
+
+		return parts[1], true
+	default:
+		return "", false
+	}
+}
+
+// DiscoverTaggedResources finds resources of the given types carrying every
+// one of the given tags, returning a definition for each named after its
+// identifier
+func DiscoverTaggedResources(
+	client *resourcegroupstaggingapi.Client,
+	tags map[string]string,
+	resourceTypes []types.ResourceType,
+) ([]types.ResourceDefinition, error) {
+	tagFilters := []tagging_types.TagFilter{}
+	for key, value := range tags {
+		tagFilters = append(tagFilters, tagging_types.TagFilter{
+			Key:    &key,
+			Values: []string{value},
+		})
+	}
+
+	resources := []types.ResourceDefinition{}
+	for _, resourceType := range resourceTypes {
+		filter, found := taggingResourceTypes[resourceType]
+		if !found {
+			return nil, fmt.Errorf("resource type can't be discovered by tags: %s", resourceType)
+		}
+
+		paginator := resourcegroupstaggingapi.NewGetResourcesPaginator(client, &resourcegroupstaggingapi.GetResourcesInput{
+			TagFilters:          tagFilters,
+			ResourceTypeFilters: []string{filter},
+		})
+
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(context.TODO())
+			if err != nil {
+				return nil, err
+			}
+
+			for _, mapping := range page.ResourceTagMappingList {
+				identifier, ok := identifierFromARN(resourceType, *mapping.ResourceARN)
+				if !ok {
+					continue
+				}
+
+				resources = append(resources, types.ResourceDefinition{
+					Name:       identifier,
+					Identifier: identifier,
+					Type:       resourceType,
+				})
+			}
+		}
+	}
+
+	return resources, nil
+}
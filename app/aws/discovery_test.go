@@ -0,0 +1,38 @@
+package aws
+
+import (
+	"hermes/app/types"
+	"testing"
+)
+
+func TestIdentifierFromARN(t *testing.T) {
+	tests := []struct {
+		name         string
+		resourceType types.ResourceType
+		arn          string
+		want         string
+		wantOK       bool
+	}{
+		{"ecs cluster", types.ECSResource, "arn:aws:ecs:us-east-1:123456789012:cluster/api", "api", true},
+		{"rds instance", types.RDSResource, "arn:aws:rds:us-east-1:123456789012:db:api-db", "api-db", true},
+		{"application load balancer", types.ELBResource, "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/api-alb/50dc6c495c0c9188", "api-alb", true},
+		{"network load balancer", types.ELBResource, "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/net/api-nlb/50dc6c495c0c9188", "api-nlb", true},
+		{"gateway load balancer", types.ELBResource, "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/gwy/api-gwlb/50dc6c495c0c9188", "", false},
+		{"target group", types.ELBResource, "arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/api/50dc6c495c0c9188", "", false},
+		{"lambda function", types.LambdaResource, "arn:aws:lambda:us-east-1:123456789012:function:handler", "handler", true},
+		{"lambda version", types.LambdaResource, "arn:aws:lambda:us-east-1:123456789012:function:handler:7", "handler", true},
+		{"lambda alias", types.LambdaResource, "arn:aws:lambda:us-east-1:123456789012:function:handler:live", "handler", true},
+		{"lambda layer", types.LambdaResource, "arn:aws:lambda:us-east-1:123456789012:layer:deps:3", "", false},
+		{"not an arn", types.ECSResource, "api", "", false},
+		{"unsupported type", types.DockerContainerResource, "arn:aws:ecs:us-east-1:123456789012:cluster/api", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := identifierFromARN(test.resourceType, test.arn)
+			if got != test.want || ok != test.wantOK {
+				t.Errorf("got (%q, %t), want (%q, %t)", got, ok, test.want, test.wantOK)
+			}
+		})
+	}
+}
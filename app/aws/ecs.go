@@ -2,10 +2,11 @@ package aws
 
 import (
 	"context"
+	"fmt"
 	"hermes/app/types"
+	"strings"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
 )
 
@@ -21,8 +22,30 @@ type ECSStatus struct {
 
 func (e ECSStatus) IsResourceStatus() {}
 
-func (e ECSStatus) IsHealthy() bool {
-	return e.Status == "ACTIVE"
+// GetHealth reports a cluster as degraded while any of its services runs
+// fewer tasks than it wants, e.g. during a deployment or after a task crash
+func (e ECSStatus) GetHealth() types.Health {
+	if !e.InstanceExists {
+		return types.Unhealthy("not found")
+	}
+
+	if e.Status != "ACTIVE" {
+		return types.Unhealthy(fmt.Sprintf("cluster is %s", e.Status))
+	}
+
+	reasons := []string{}
+	for _, service := range e.Services {
+		if service.RunningCount < service.DesiredCount {
+			reasons = append(reasons,
+				fmt.Sprintf("%s running %d/%d tasks", service.Name, service.RunningCount, service.DesiredCount))
+		}
+	}
+
+	if len(reasons) > 0 {
+		return types.Degraded(strings.Join(reasons, ", "))
+	}
+
+	return types.Healthy()
 }
 
 func (e ECSStatus) Exists() bool {
@@ -98,12 +121,3 @@ func GetECSStatus(client *ecs.Client, clusterIdentifier string) (ECSStatus, erro
 		Services:       services,
 	}, nil
 }
-
-func GetECSClient() (*ecs.Client, error) {
-	cfg, err := config.LoadDefaultConfig(context.TODO())
-	if err != nil {
-		return nil, err
-	}
-
-	return ecs.NewFromConfig(cfg), nil
-}
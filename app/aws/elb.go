@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"hermes/app/types"
 
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
 	elb_types "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
 )
@@ -14,15 +13,26 @@ import (
 var _ types.ResourceStatus = ELBStatus{}
 
 type ELBStatus struct {
-	InstanceExists bool                            `json:"exists"`
-	Status         elb_types.LoadBalancerStateEnum `json:"status"`
-	DNSName        string                          `json:"dns_name"`
+	InstanceExists   bool                             `json:"exists"`
+	Status           elb_types.LoadBalancerStateEnum  `json:"status"`
+	DNSName          string                           `json:"dns_name"`
+	Scheme           elb_types.LoadBalancerSchemeEnum `json:"scheme"`
+	LoadBalancerType elb_types.LoadBalancerTypeEnum   `json:"type"`
 }
 
 func (e ELBStatus) IsResourceStatus() {}
 
-func (e ELBStatus) IsHealthy() bool {
-	return e.Status == "active"
+func (e ELBStatus) GetHealth() types.Health {
+	switch {
+	case !e.InstanceExists:
+		return types.Unhealthy("not found")
+	case e.Status == elb_types.LoadBalancerStateEnumActive:
+		return types.Healthy()
+	case e.Status == elb_types.LoadBalancerStateEnumActiveImpaired:
+		return types.Degraded("load balancer is impaired")
+	default:
+		return types.Unhealthy(fmt.Sprintf("load balancer is %s", e.Status))
+	}
 }
 
 func (e ELBStatus) Exists() bool {
@@ -56,17 +66,10 @@ func GetELBStatus(client *elasticloadbalancingv2.Client, elbName string) (ELBSta
 	loadBalancer := result.LoadBalancers[0]
 
 	return ELBStatus{
-		InstanceExists: true,
-		Status:         loadBalancer.State.Code,
-		DNSName:        *loadBalancer.DNSName,
+		InstanceExists:   true,
+		Status:           loadBalancer.State.Code,
+		DNSName:          *loadBalancer.DNSName,
+		Scheme:           loadBalancer.Scheme,
+		LoadBalancerType: loadBalancer.Type,
 	}, nil
 }
-
-func GetELBClient() (*elasticloadbalancingv2.Client, error) {
-	cfg, err := config.LoadDefaultConfig(context.TODO())
-	if err != nil {
-		return nil, err
-	}
-
-	return elasticloadbalancingv2.NewFromConfig(cfg), nil
-}
@@ -0,0 +1,96 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hermes/app/types"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambda_types "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+var _ types.ResourceStatus = LambdaStatus{}
+
+type LambdaStatus struct {
+	InstanceExists   bool   `json:"exists"`
+	State            string `json:"state"`
+	StateReason      string `json:"state_reason,omitempty"`
+	LastUpdateStatus string `json:"last_update_status"`
+	LastUpdateReason string `json:"last_update_reason,omitempty"`
+	Runtime          string `json:"runtime"`
+	Version          string `json:"version"`
+	LastModified     string `json:"last_modified"`
+}
+
+func (l LambdaStatus) IsResourceStatus() {}
+
+// GetHealth reports a function whose last update failed as degraded, as it
+// keeps serving the code it had before the update
+func (l LambdaStatus) GetHealth() types.Health {
+	if !l.InstanceExists {
+		return types.Unhealthy("not found")
+	}
+
+	if l.State != string(lambda_types.StateActive) {
+		return types.Unhealthy(stateReason(l.State, l.StateReason))
+	}
+
+	if l.LastUpdateStatus == string(lambda_types.LastUpdateStatusFailed) {
+		return types.Degraded(stateReason("update failed", l.LastUpdateReason))
+	}
+
+	return types.Healthy()
+}
+
+// stateReason appends the reason aws gave for a state, where there is one
+func stateReason(state string, reason string) string {
+	if reason == "" {
+		return state
+	}
+
+	return fmt.Sprintf("%s: %s", state, reason)
+}
+
+func (l LambdaStatus) Exists() bool {
+	return l.InstanceExists
+}
+
+func (l LambdaStatus) GetStatusString() string {
+	if l.LastUpdateStatus == string(lambda_types.LastUpdateStatusFailed) {
+		return "UpdateFailed"
+	}
+
+	return l.State
+}
+
+func GetLambdaStatus(client *lambda.Client, functionName string) (LambdaStatus, error) {
+	resp, err := client.GetFunction(context.TODO(), &lambda.GetFunctionInput{
+		FunctionName: aws.String(functionName),
+	})
+
+	if err != nil {
+		var notFound *lambda_types.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return LambdaStatus{
+				InstanceExists: false,
+			}, nil
+		}
+
+		return LambdaStatus{}, err
+	}
+
+	configuration := resp.Configuration
+
+	return LambdaStatus{
+		InstanceExists:   true,
+		State:            string(configuration.State),
+		StateReason:      aws.ToString(configuration.StateReason),
+		LastUpdateStatus: string(configuration.LastUpdateStatus),
+		LastUpdateReason: aws.ToString(configuration.LastUpdateStatusReason),
+		Runtime:          string(configuration.Runtime),
+		Version:          aws.ToString(configuration.Version),
+		LastModified:     aws.ToString(configuration.LastModified),
+	}, nil
+}
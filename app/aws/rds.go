@@ -5,25 +5,65 @@ import (
 	"errors"
 	"fmt"
 	"hermes/app/types"
+	"log"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cloudwatch_types "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 	"github.com/aws/aws-sdk-go-v2/service/rds"
 	rds_types "github.com/aws/aws-sdk-go-v2/service/rds/types"
 )
 
+const (
+	bytesPerGiB = 1024 * 1024 * 1024
+
+	// how far back to look for rds events, and how many of them to keep
+	rdsEventWindow    = 24 * time.Hour
+	rdsMaxEventsShown = 10
+)
+
 var _ types.ResourceStatus = RDSStatus{}
 
 type RDSStatus struct {
-	InstanceExists bool   `json:"exists"`
-	Status         string `json:"status"`
-	InstanceClass  string `json:"instance_class"`
+	InstanceExists       bool                   `json:"exists"`
+	Status               string                 `json:"status"`
+	InstanceClass        string                 `json:"instance_class"`
+	Engine               string                 `json:"engine"`
+	EngineVersion        string                 `json:"engine_version"`
+	MultiAZ              bool                   `json:"multi_az"`
+	AllocatedStorageGiB  int                    `json:"allocated_storage_gib"`
+	FreeStorageGiB       *float64               `json:"free_storage_gib"`
+	BackupRetentionDays  int                    `json:"backup_retention_days"`
+	LatestRestorableTime *time.Time             `json:"latest_restorable_time"`
+	PendingMaintenance   []RDSMaintenanceAction `json:"pending_maintenance"`
+	RecentEvents         []RDSEvent             `json:"recent_events"`
+
+	thresholds types.RDSOptions
 }
 
 func (r RDSStatus) IsResourceStatus() {}
 
-func (r RDSStatus) IsHealthy() bool {
-	return r.Status == "available"
+// GetHealth reports an available instance that is low on storage or behind
+// on backups as degraded, since it is still serving
+func (r RDSStatus) GetHealth() types.Health {
+	if !r.InstanceExists {
+		return types.Unhealthy("not found")
+	}
+
+	if r.Status != "available" {
+		return types.Unhealthy(fmt.Sprintf("instance is %s", r.Status))
+	}
+
+	if r.storageLow() {
+		return types.Degraded(fmt.Sprintf("%.1f GiB of storage free", *r.FreeStorageGiB))
+	}
+
+	if r.backupsStale() {
+		return types.Degraded("no recent backup")
+	}
+
+	return types.Healthy()
 }
 
 func (r RDSStatus) Exists() bool {
@@ -31,10 +71,147 @@ func (r RDSStatus) Exists() bool {
 }
 
 func (r RDSStatus) GetStatusString() string {
+	if r.Status == "available" {
+		if r.storageLow() {
+			return "storage-low"
+		}
+
+		if r.backupsStale() {
+			return "backups-stale"
+		}
+	}
+
 	return r.Status
 }
 
-func GetRDSStatus(client *rds.Client, dbIdentifier string) (RDSStatus, error) {
+// storageLow reports whether free storage has dropped below the configured
+// threshold. Instances without a FreeStorageSpace metric (e.g. Aurora) are
+// never considered low
+func (r RDSStatus) storageLow() bool {
+	if r.thresholds.MinFreeStorageGiB == 0 || r.FreeStorageGiB == nil {
+		return false
+	}
+
+	return *r.FreeStorageGiB < r.thresholds.MinFreeStorageGiB
+}
+
+// backupsStale reports whether the latest restorable time is older than the
+// configured maximum backup age, or backups are disabled entirely
+func (r RDSStatus) backupsStale() bool {
+	if r.thresholds.MaxBackupAge == 0 {
+		return false
+	}
+
+	if r.LatestRestorableTime == nil {
+		return true
+	}
+
+	return time.Since(*r.LatestRestorableTime) > r.thresholds.MaxBackupAge
+}
+
+type RDSMaintenanceAction struct {
+	Action           string     `json:"action"`
+	Description      string     `json:"description"`
+	AutoAppliedAfter *time.Time `json:"auto_applied_after"`
+	ForcedApplyDate  *time.Time `json:"forced_apply_date"`
+}
+
+type RDSEvent struct {
+	Date       time.Time `json:"date"`
+	Message    string    `json:"message"`
+	Categories []string  `json:"categories"`
+}
+
+func getRDSFreeStorage(client *cloudwatch.Client, dbIdentifier string) (*float64, error) {
+	freeBytes, found, err := getLatestMetricMinimum(
+		client,
+		"AWS/RDS",
+		"FreeStorageSpace",
+		[]cloudwatch_types.Dimension{
+			{
+				Name:  aws.String("DBInstanceIdentifier"),
+				Value: aws.String(dbIdentifier),
+			},
+		},
+	)
+
+	if err != nil || !found {
+		return nil, err
+	}
+
+	freeGiB := freeBytes / bytesPerGiB
+
+	return &freeGiB, nil
+}
+
+func getRDSPendingMaintenance(client *rds.Client, dbArn string) ([]RDSMaintenanceAction, error) {
+	paginator := rds.NewDescribePendingMaintenanceActionsPaginator(client, &rds.DescribePendingMaintenanceActionsInput{
+		ResourceIdentifier: aws.String(dbArn),
+	})
+
+	actions := []RDSMaintenanceAction{}
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+
+		for _, resource := range page.PendingMaintenanceActions {
+			for _, action := range resource.PendingMaintenanceActionDetails {
+				actions = append(actions,
+					RDSMaintenanceAction{
+						Action:           aws.ToString(action.Action),
+						Description:      aws.ToString(action.Description),
+						AutoAppliedAfter: action.AutoAppliedAfterDate,
+						ForcedApplyDate:  action.ForcedApplyDate,
+					},
+				)
+			}
+		}
+	}
+
+	return actions, nil
+}
+
+func getRDSRecentEvents(client *rds.Client, dbIdentifier string) ([]RDSEvent, error) {
+	paginator := rds.NewDescribeEventsPaginator(client, &rds.DescribeEventsInput{
+		SourceType:       rds_types.SourceTypeDbInstance,
+		SourceIdentifier: aws.String(dbIdentifier),
+		Duration:         aws.Int32(int32(rdsEventWindow.Minutes())),
+	})
+
+	events := []RDSEvent{}
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+
+		for _, event := range page.Events {
+			events = append(events,
+				RDSEvent{
+					Date:       aws.ToTime(event.Date),
+					Message:    aws.ToString(event.Message),
+					Categories: event.EventCategories,
+				},
+			)
+		}
+	}
+
+	// events are returned oldest first, only the tail is interesting
+	if len(events) > rdsMaxEventsShown {
+		events = events[len(events)-rdsMaxEventsShown:]
+	}
+
+	return events, nil
+}
+
+func GetRDSStatus(
+	client *rds.Client,
+	cloudwatchClient *cloudwatch.Client,
+	dbIdentifier string,
+	options *types.RDSOptions,
+) (RDSStatus, error) {
 	resp, err := client.DescribeDBInstances(context.TODO(), &rds.DescribeDBInstancesInput{
 		DBInstanceIdentifier: aws.String(dbIdentifier),
 	})
@@ -56,18 +233,41 @@ func GetRDSStatus(client *rds.Client, dbIdentifier string) (RDSStatus, error) {
 
 	firstDb := resp.DBInstances[0]
 
-	return RDSStatus{
-		InstanceExists: true,
-		Status:         *firstDb.DBInstanceStatus,
-		InstanceClass:  *firstDb.DBInstanceClass,
-	}, nil
-}
+	// cloudwatch access is a separate permission, so a failure here leaves
+	// free storage unknown rather than failing the whole check
+	freeStorage, err := getRDSFreeStorage(cloudwatchClient, dbIdentifier)
+	if err != nil {
+		log.Println("error fetching rds free storage", dbIdentifier, err)
+	}
 
-func GetRDSClient() (*rds.Client, error) {
-	cfg, err := config.LoadDefaultConfig(context.TODO())
+	pendingMaintenance, err := getRDSPendingMaintenance(client, aws.ToString(firstDb.DBInstanceArn))
 	if err != nil {
-		return nil, err
+		return RDSStatus{}, err
+	}
+
+	recentEvents, err := getRDSRecentEvents(client, dbIdentifier)
+	if err != nil {
+		return RDSStatus{}, err
 	}
 
-	return rds.NewFromConfig(cfg), nil
+	thresholds := types.RDSOptions{}
+	if options != nil {
+		thresholds = *options
+	}
+
+	return RDSStatus{
+		InstanceExists:       true,
+		Status:               *firstDb.DBInstanceStatus,
+		InstanceClass:        *firstDb.DBInstanceClass,
+		Engine:               aws.ToString(firstDb.Engine),
+		EngineVersion:        aws.ToString(firstDb.EngineVersion),
+		MultiAZ:              aws.ToBool(firstDb.MultiAZ),
+		AllocatedStorageGiB:  int(aws.ToInt32(firstDb.AllocatedStorage)),
+		FreeStorageGiB:       freeStorage,
+		BackupRetentionDays:  int(aws.ToInt32(firstDb.BackupRetentionPeriod)),
+		LatestRestorableTime: firstDb.LatestRestorableTime,
+		PendingMaintenance:   pendingMaintenance,
+		RecentEvents:         recentEvents,
+		thresholds:           thresholds,
+	}, nil
 }
@@ -0,0 +1,113 @@
+package aws
+
+import (
+	"hermes/app/types"
+	"testing"
+	"time"
+)
+
+func TestRDSStatusGetHealth(t *testing.T) {
+	thresholds := types.RDSOptions{MinFreeStorageGiB: 10, MaxBackupAge: 26 * time.Hour}
+	recentBackup := time.Now().Add(-2 * time.Hour)
+	oldBackup := time.Now().Add(-30 * time.Hour)
+	lowStorage := 4.5
+	plentyOfStorage := 50.0
+
+	tests := []struct {
+		name       string
+		status     RDSStatus
+		wantHealth types.HealthState
+		wantString string
+	}{
+		{
+			name:       "within thresholds",
+			status:     RDSStatus{InstanceExists: true, Status: "available", FreeStorageGiB: &plentyOfStorage, LatestRestorableTime: &recentBackup},
+			wantHealth: types.HealthHealthy,
+			wantString: "available",
+		},
+		{
+			name:       "free storage unknown",
+			status:     RDSStatus{InstanceExists: true, Status: "available", LatestRestorableTime: &recentBackup},
+			wantHealth: types.HealthHealthy,
+			wantString: "available",
+		},
+		{
+			name:       "storage low",
+			status:     RDSStatus{InstanceExists: true, Status: "available", FreeStorageGiB: &lowStorage, LatestRestorableTime: &recentBackup},
+			wantHealth: types.HealthDegraded,
+			wantString: "storage-low",
+		},
+		{
+			name:       "backup older than the maximum age",
+			status:     RDSStatus{InstanceExists: true, Status: "available", FreeStorageGiB: &plentyOfStorage, LatestRestorableTime: &oldBackup},
+			wantHealth: types.HealthDegraded,
+			wantString: "backups-stale",
+		},
+		{
+			name:       "no backup",
+			status:     RDSStatus{InstanceExists: true, Status: "available", FreeStorageGiB: &plentyOfStorage},
+			wantHealth: types.HealthDegraded,
+			wantString: "backups-stale",
+		},
+		{
+			name:       "not available",
+			status:     RDSStatus{InstanceExists: true, Status: "stopped"},
+			wantHealth: types.HealthUnhealthy,
+			wantString: "stopped",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.status.thresholds = thresholds
+
+			if health := test.status.GetHealth(); health.State != test.wantHealth {
+				t.Errorf("got health %s (%s), want %s", health.State, health.Reason, test.wantHealth)
+			}
+
+			if test.status.GetStatusString() != test.wantString {
+				t.Errorf("got status %s, want %s", test.status.GetStatusString(), test.wantString)
+			}
+		})
+	}
+}
+
+func TestRDSStatusBackupsStale(t *testing.T) {
+	backup := time.Now().Add(-2 * time.Hour)
+
+	tests := []struct {
+		name         string
+		maxBackupAge time.Duration
+		backup       *time.Time
+		want         bool
+	}{
+		{"check disabled", 0, nil, false},
+		{"backup within the maximum age", 26 * time.Hour, &backup, false},
+		{"backup older than the maximum age", 90 * time.Minute, &backup, true},
+		{"no backup", 26 * time.Hour, nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status := RDSStatus{
+				InstanceExists:       true,
+				Status:               "available",
+				LatestRestorableTime: test.backup,
+				thresholds:           types.RDSOptions{MaxBackupAge: test.maxBackupAge},
+			}
+
+			if got := status.backupsStale(); got != test.want {
+				t.Errorf("got stale %t, want %t", got, test.want)
+			}
+
+			wantHealth := types.HealthHealthy
+			if test.want {
+				wantHealth = types.HealthDegraded
+			}
+
+			if health := status.GetHealth(); health.State != wantHealth {
+				t.Errorf("got health %s (%s), want %s", health.State, health.Reason, wantHealth)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hermes/app/httpapi"
+	"hermes/app/types"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const (
+	defaultEndpoint      = "https://management.azure.com"
+	defaultLoginEndpoint = "https://login.microsoftonline.com"
+)
+
+const requestTimeout = 10 * time.Second
+
+var errNotFound = errors.New("azure resource not found")
+
+// the service principal environment variables, named as the azure sdks and
+// cli expect them
+const (
+	tenantIDEnv       = "AZURE_TENANT_ID"
+	clientIDEnv       = "AZURE_CLIENT_ID"
+	clientSecretEnv   = "AZURE_CLIENT_SECRET"
+	subscriptionIDEnv = "AZURE_SUBSCRIPTION_ID"
+)
+
+// Client is a minimal Azure Resource Manager REST client
+type Client struct {
+	httpClient     *http.Client
+	endpoint       string
+	subscriptionID string
+}
+
+// skipsAuth reports whether requests are made without a service principal,
+// either because the config opts out or the endpoint is a local fake
+func skipsAuth(definition *types.AzureDefinition) bool {
+	return definition.InsecureNoAuth ||
+		(definition.Endpoint != "" && httpapi.IsLocalEndpoint(definition.Endpoint))
+}
+
+// RequiredEnvVars returns the service principal variables needed to build a
+// client for the given config
+func RequiredEnvVars(definition *types.AzureDefinition) []string {
+	if definition != nil && skipsAuth(definition) {
+		return []string{}
+	}
+
+	required := []string{tenantIDEnv, clientIDEnv, clientSecretEnv}
+	if definition == nil || definition.SubscriptionID == "" {
+		required = append(required, subscriptionIDEnv)
+	}
+
+	return required
+}
+
+// NewClient builds a client from the azure section of the config, which may
+// be nil. Tokens are acquired with the client credentials of the service
+// principal in the environment, scoped to the endpoint. Requests are made
+// unauthenticated when insecure_no_auth is set or the endpoint is local, so
+// a fake can be used
+func NewClient(definition *types.AzureDefinition) *Client {
+	if definition == nil {
+		definition = &types.AzureDefinition{}
+	}
+
+	client := &Client{
+		httpClient:     &http.Client{Timeout: requestTimeout},
+		endpoint:       defaultEndpoint,
+		subscriptionID: definition.SubscriptionID,
+	}
+
+	if client.subscriptionID == "" {
+		client.subscriptionID = os.Getenv(subscriptionIDEnv)
+	}
+
+	if definition.Endpoint != "" {
+		client.endpoint = strings.TrimSuffix(definition.Endpoint, "/")
+	}
+
+	if skipsAuth(definition) {
+		log.Println("azure credentials skipped, requests to", client.endpoint, "are unauthenticated")
+		return client
+	}
+
+	loginEndpoint := defaultLoginEndpoint
+	if definition.LoginEndpoint != "" {
+		loginEndpoint = strings.TrimSuffix(definition.LoginEndpoint, "/")
+	}
+
+	credentials := clientcredentials.Config{
+		ClientID:     os.Getenv(clientIDEnv),
+		ClientSecret: os.Getenv(clientSecretEnv),
+		TokenURL:     fmt.Sprintf("%s/%s/oauth2/v2.0/token", loginEndpoint, os.Getenv(tenantIDEnv)),
+		Scopes:       []string{client.endpoint + "/.default"},
+	}
+
+	client.httpClient = credentials.Client(context.Background())
+	client.httpClient.Timeout = requestTimeout
+
+	return client
+}
+
+// get decodes the json response for an arm resource path into out,
+// returning errNotFound on a 404
+func (c *Client) get(path string, apiVersion string, out any) error {
+	resp, err := c.httpClient.Get(c.endpoint + path + "?api-version=" + apiVersion)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return httpapi.ResponseError("azure", resp, errorMessage)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// errorMessage returns the code and message of an arm error
+func errorMessage(body []byte) string {
+	var response struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	if json.Unmarshal(body, &response) != nil || response.Error.Code == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s: %s", response.Error.Code, response.Error.Message)
+}
+
+// resourceGroupPath splits an identifier of the form
+// [subscription/]resourceGroup/name..., returning the resource group's arm
+// path and the remaining parts. want is the number of parts after the
+// resource group
+func (c *Client) resourceGroupPath(identifier string, want int) (string, []string, error) {
+	parts := strings.Split(identifier, "/")
+
+	subscriptionID := c.subscriptionID
+	switch len(parts) {
+	case want + 1:
+	case want + 2:
+		subscriptionID, parts = parts[0], parts[1:]
+	default:
+		return "", nil, fmt.Errorf("invalid azure identifier: %s", identifier)
+	}
+
+	if subscriptionID == "" {
+		return "", nil, fmt.Errorf("azure identifier needs a subscription: %s", identifier)
+	}
+
+	path := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", subscriptionID, parts[0])
+
+	return path, parts[1:], nil
+}
@@ -0,0 +1,148 @@
+package azure
+
+import (
+	"fmt"
+	"hermes/app/types"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestResourceGroupPath(t *testing.T) {
+	client := &Client{subscriptionID: "sub-default"}
+
+	tests := []struct {
+		name       string
+		client     *Client
+		identifier string
+		want       int
+		wantPath   string
+		wantParts  []string
+		wantErr    bool
+	}{
+		{
+			name:       "default subscription",
+			client:     client,
+			identifier: "shop-rg/shop-web",
+			want:       1,
+			wantPath:   "/subscriptions/sub-default/resourceGroups/shop-rg",
+			wantParts:  []string{"shop-web"},
+		},
+		{
+			name:       "explicit subscription",
+			client:     client,
+			identifier: "sub-other/shop-rg/shop-web",
+			want:       1,
+			wantPath:   "/subscriptions/sub-other/resourceGroups/shop-rg",
+			wantParts:  []string{"shop-web"},
+		},
+		{
+			name:       "nested resource",
+			client:     client,
+			identifier: "shop-rg/shop-sql/orders",
+			want:       2,
+			wantPath:   "/subscriptions/sub-default/resourceGroups/shop-rg",
+			wantParts:  []string{"shop-sql", "orders"},
+		},
+		{
+			name:       "nested resource with subscription",
+			client:     client,
+			identifier: "sub-other/shop-rg/shop-sql/orders",
+			want:       2,
+			wantPath:   "/subscriptions/sub-other/resourceGroups/shop-rg",
+			wantParts:  []string{"shop-sql", "orders"},
+		},
+		{
+			name:       "too few parts",
+			client:     client,
+			identifier: "shop-web",
+			want:       1,
+			wantErr:    true,
+		},
+		{
+			name:       "too many parts",
+			client:     client,
+			identifier: "a/b/c/d",
+			want:       1,
+			wantErr:    true,
+		},
+		{
+			name:       "no subscription",
+			client:     &Client{},
+			identifier: "shop-rg/shop-web",
+			want:       1,
+			wantErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, parts, err := test.client.resourceGroupPath(test.identifier, test.want)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error %t", err, test.wantErr)
+			}
+
+			if path != test.wantPath || !reflect.DeepEqual(parts, test.wantParts) {
+				t.Errorf("got (%s, %v), want (%s, %v)", path, parts, test.wantPath, test.wantParts)
+			}
+		})
+	}
+}
+
+func TestNewClientAuth(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition types.AzureDefinition
+		wantAuth   bool
+	}{
+		{"default endpoint", types.AzureDefinition{}, true},
+		{"remote endpoint", types.AzureDefinition{Endpoint: "https://management.chinacloudapi.cn"}, true},
+		{"local endpoint", types.AzureDefinition{Endpoint: "http://localhost:8080"}, false},
+		{"insecure no auth", types.AzureDefinition{Endpoint: "https://arm.example.com", InsecureNoAuth: true}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			required := RequiredEnvVars(&test.definition)
+			if (len(required) > 0) != test.wantAuth {
+				t.Errorf("got required env vars %v, want auth %t", required, test.wantAuth)
+			}
+
+			client := NewClient(&test.definition)
+			if (client.httpClient.Transport != nil) != test.wantAuth {
+				t.Errorf("got transport %T, want auth %t", client.httpClient.Transport, test.wantAuth)
+			}
+		})
+	}
+}
+
+func TestNewClientScope(t *testing.T) {
+	scopes := make(chan string, 1)
+	login := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scopes <- r.FormValue("scope")
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token": "token", "token_type": "Bearer", "expires_in": 3600}`)
+	}))
+	t.Cleanup(login.Close)
+
+	t.Setenv(tenantIDEnv, "tenant")
+	t.Setenv(clientIDEnv, "client")
+	t.Setenv(clientSecretEnv, "secret")
+
+	client := NewClient(&types.AzureDefinition{
+		SubscriptionID: "sub-1",
+		Endpoint:       "https://management.example.invalid/",
+		LoginEndpoint:  login.URL,
+	})
+
+	// the token is requested before the api, which doesn't resolve
+	var out any
+	client.get("/subscriptions/sub-1", "2022-09-01", &out)
+
+	want := "https://management.example.invalid/.default"
+	if got := <-scopes; got != want {
+		t.Errorf("got scope %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,89 @@
+package azure
+
+import (
+	"errors"
+	"fmt"
+	"hermes/app/types"
+	"strings"
+)
+
+const sqlAPIVersion = "2021-11-01"
+
+var _ types.ResourceStatus = SQLStatus{}
+
+type SQLStatus struct {
+	InstanceExists   bool   `json:"exists"`
+	Status           string `json:"status"`
+	SKU              string `json:"sku"`
+	Tier             string `json:"tier"`
+	ServiceObjective string `json:"service_objective"`
+	ZoneRedundant    bool   `json:"zone_redundant"`
+}
+
+func (s SQLStatus) IsResourceStatus() {}
+
+func (s SQLStatus) GetHealth() types.Health {
+	switch {
+	case !s.InstanceExists:
+		return types.Unhealthy("not found")
+	case s.Status == "Online":
+		return types.Healthy()
+	case s.Status == "Scaling":
+		return types.Degraded("database is scaling")
+	default:
+		return types.Unhealthy(fmt.Sprintf("database is %s", s.GetStatusString()))
+	}
+}
+
+func (s SQLStatus) Exists() bool {
+	return s.InstanceExists
+}
+
+func (s SQLStatus) GetStatusString() string {
+	return strings.ToLower(s.Status)
+}
+
+// GetSQLStatus reads an Azure SQL database, identified by
+// [subscription/]resourceGroup/server/database
+func GetSQLStatus(client *Client, identifier string) (SQLStatus, error) {
+	resourceGroupPath, parts, err := client.resourceGroupPath(identifier, 2)
+	if err != nil {
+		return SQLStatus{}, err
+	}
+
+	var database struct {
+		SKU struct {
+			Name string `json:"name"`
+			Tier string `json:"tier"`
+		} `json:"sku"`
+		Properties struct {
+			Status                      string `json:"status"`
+			CurrentServiceObjectiveName string `json:"currentServiceObjectiveName"`
+			ZoneRedundant               bool   `json:"zoneRedundant"`
+		} `json:"properties"`
+	}
+
+	err = client.get(
+		resourceGroupPath+"/providers/Microsoft.Sql/servers/"+parts[0]+"/databases/"+parts[1],
+		sqlAPIVersion,
+		&database,
+	)
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			return SQLStatus{
+				InstanceExists: false,
+			}, nil
+		}
+
+		return SQLStatus{}, err
+	}
+
+	return SQLStatus{
+		InstanceExists:   true,
+		Status:           database.Properties.Status,
+		SKU:              database.SKU.Name,
+		Tier:             database.SKU.Tier,
+		ServiceObjective: database.Properties.CurrentServiceObjectiveName,
+		ZoneRedundant:    database.Properties.ZoneRedundant,
+	}, nil
+}
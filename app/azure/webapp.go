@@ -0,0 +1,164 @@
+package azure
+
+import (
+	"errors"
+	"fmt"
+	"hermes/app/types"
+	"time"
+)
+
+const webAPIVersion = "2023-12-01"
+
+var _ types.ResourceStatus = WebAppStatus{}
+
+type WebAppStatus struct {
+	InstanceExists bool   `json:"exists"`
+	State          string `json:"state"`
+	// Normal, Limited or DisasterRecoveryMode
+	AvailabilityState string            `json:"availability_state"`
+	DefaultHostName   string            `json:"default_host_name"`
+	LastDeployment    *WebAppDeployment `json:"last_deployment"`
+}
+
+type WebAppDeployment struct {
+	ID        string    `json:"id"`
+	Status    string    `json:"status"`
+	Author    string    `json:"author"`
+	Deployer  string    `json:"deployer"`
+	Message   string    `json:"message"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+func (w WebAppStatus) IsResourceStatus() {}
+
+// GetHealth reports a running app whose last deployment failed, or whose
+// availability is limited, as degraded
+func (w WebAppStatus) GetHealth() types.Health {
+	switch {
+	case !w.InstanceExists:
+		return types.Unhealthy("not found")
+	case w.State != "Running":
+		return types.Unhealthy(fmt.Sprintf("app is %s", w.State))
+	case w.LastDeployment != nil && w.LastDeployment.Status == "failed":
+		return types.Degraded(fmt.Sprintf("deployment %s failed", w.LastDeployment.ID))
+	case w.AvailabilityState != "Normal":
+		return types.Degraded(fmt.Sprintf("availability is %s", w.AvailabilityState))
+	default:
+		return types.Healthy()
+	}
+}
+
+func (w WebAppStatus) Exists() bool {
+	return w.InstanceExists
+}
+
+func (w WebAppStatus) GetStatusString() string {
+	if w.LastDeployment != nil && w.LastDeployment.Status == "failed" {
+		return "deployment-failed"
+	}
+
+	if w.State == "Running" && w.AvailabilityState != "Normal" {
+		return "limited"
+	}
+
+	return w.State
+}
+
+// deployment status codes reported by kudu
+var deploymentStatuses = map[int]string{
+	0: "pending",
+	1: "building",
+	2: "deploying",
+	3: "failed",
+	4: "success",
+}
+
+func getLastDeployment(client *Client, sitePath string) (*WebAppDeployment, error) {
+	var deployments struct {
+		Value []struct {
+			Name       string `json:"name"`
+			Properties struct {
+				Status    int       `json:"status"`
+				Author    string    `json:"author"`
+				Deployer  string    `json:"deployer"`
+				Message   string    `json:"message"`
+				StartTime time.Time `json:"start_time"`
+				EndTime   time.Time `json:"end_time"`
+			} `json:"properties"`
+		} `json:"value"`
+	}
+
+	err := client.get(sitePath+"/deployments", webAPIVersion, &deployments)
+	if err != nil {
+		return nil, err
+	}
+
+	// the listing isn't ordered, so take the most recently started
+	var last *WebAppDeployment
+	for _, deployment := range deployments.Value {
+		if last != nil && !deployment.Properties.StartTime.After(last.StartTime) {
+			continue
+		}
+
+		status, found := deploymentStatuses[deployment.Properties.Status]
+		if !found {
+			status = fmt.Sprint(deployment.Properties.Status)
+		}
+
+		last = &WebAppDeployment{
+			ID:        deployment.Name,
+			Status:    status,
+			Author:    deployment.Properties.Author,
+			Deployer:  deployment.Properties.Deployer,
+			Message:   deployment.Properties.Message,
+			StartTime: deployment.Properties.StartTime,
+			EndTime:   deployment.Properties.EndTime,
+		}
+	}
+
+	return last, nil
+}
+
+// GetWebAppStatus reads an App Service site, identified by
+// [subscription/]resourceGroup/site, and its most recent deployment
+func GetWebAppStatus(client *Client, identifier string) (WebAppStatus, error) {
+	resourceGroupPath, parts, err := client.resourceGroupPath(identifier, 1)
+	if err != nil {
+		return WebAppStatus{}, err
+	}
+
+	sitePath := resourceGroupPath + "/providers/Microsoft.Web/sites/" + parts[0]
+
+	var site struct {
+		Properties struct {
+			State             string `json:"state"`
+			AvailabilityState string `json:"availabilityState"`
+			DefaultHostName   string `json:"defaultHostName"`
+		} `json:"properties"`
+	}
+
+	err = client.get(sitePath, webAPIVersion, &site)
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			return WebAppStatus{
+				InstanceExists: false,
+			}, nil
+		}
+
+		return WebAppStatus{}, err
+	}
+
+	lastDeployment, err := getLastDeployment(client, sitePath)
+	if err != nil {
+		return WebAppStatus{}, err
+	}
+
+	return WebAppStatus{
+		InstanceExists:    true,
+		State:             site.Properties.State,
+		AvailabilityState: site.Properties.AvailabilityState,
+		DefaultHostName:   site.Properties.DefaultHostName,
+		LastDeployment:    lastDeployment,
+	}, nil
+}
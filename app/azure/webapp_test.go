@@ -0,0 +1,143 @@
+package azure
+
+import (
+	"fmt"
+	"hermes/app/types"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const fakeSitesPath = "/subscriptions/sub-1/resourceGroups/shop-rg/providers/Microsoft.Web/sites"
+
+// newFakeARM serves a couple of App Service sites and their deployment
+// listings, returning a client pointed at it
+func newFakeARM(t *testing.T) *Client {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(fakeSitesPath+"/{site}", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api-version") != webAPIVersion {
+			http.Error(w, "unexpected api version", http.StatusBadRequest)
+			return
+		}
+
+		switch r.PathValue("site") {
+		case "shop-web", "shop-new":
+			fmt.Fprintf(w, `{"properties": {"state": "Running", "availabilityState": "Normal", "defaultHostName": "%s.azurewebsites.net"}}`, r.PathValue("site"))
+		case "locked":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"error": {"code": "AuthorizationFailed", "message": "The client does not have authorization"}}`)
+		case "flaky":
+			w.WriteHeader(http.StatusBadGateway)
+			fmt.Fprint(w, "<html>bad gateway</html>")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": {"code": "ResourceNotFound", "message": "not found"}}`)
+		}
+	})
+	mux.HandleFunc(fakeSitesPath+"/shop-web/deployments", func(w http.ResponseWriter, r *http.Request) {
+		// deliberately out of order
+		fmt.Fprint(w, `{"value": [
+			{"name": "dep-2", "properties": {"status": 4, "author": "ana", "deployer": "GitHub", "message": "second", "start_time": "2024-01-02T00:00:00Z", "end_time": "2024-01-02T00:05:00Z"}},
+			{"name": "dep-3", "properties": {"status": 3, "author": "ben", "deployer": "GitHub", "message": "third", "start_time": "2024-01-03T00:00:00Z", "end_time": "2024-01-03T00:05:00Z"}},
+			{"name": "dep-1", "properties": {"status": 4, "author": "ana", "deployer": "GitHub", "message": "first", "start_time": "2024-01-01T00:00:00Z", "end_time": "2024-01-01T00:05:00Z"}}
+		]}`)
+	})
+	mux.HandleFunc(fakeSitesPath+"/shop-new/deployments", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"value": []}`)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	// without a service principal requests to the fake are unauthenticated
+	t.Setenv("AZURE_TENANT_ID", "")
+
+	return NewClient(&types.AzureDefinition{SubscriptionID: "sub-1", Endpoint: server.URL + "/"})
+}
+
+func TestGetWebAppStatus(t *testing.T) {
+	client := newFakeARM(t)
+
+	tests := []struct {
+		identifier string
+		want       WebAppStatus
+		wantHealth types.HealthState
+	}{
+		{
+			identifier: "shop-rg/shop-web",
+			want: WebAppStatus{
+				InstanceExists:    true,
+				State:             "Running",
+				AvailabilityState: "Normal",
+				DefaultHostName:   "shop-web.azurewebsites.net",
+				LastDeployment: &WebAppDeployment{
+					ID:        "dep-3",
+					Status:    "failed",
+					Author:    "ben",
+					Deployer:  "GitHub",
+					Message:   "third",
+					StartTime: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+					EndTime:   time.Date(2024, 1, 3, 0, 5, 0, 0, time.UTC),
+				},
+			},
+			wantHealth: types.HealthDegraded,
+		},
+		{
+			identifier: "sub-1/shop-rg/shop-new",
+			want: WebAppStatus{
+				InstanceExists:    true,
+				State:             "Running",
+				AvailabilityState: "Normal",
+				DefaultHostName:   "shop-new.azurewebsites.net",
+			},
+			wantHealth: types.HealthHealthy,
+		},
+		{
+			identifier: "shop-rg/missing",
+			want:       WebAppStatus{InstanceExists: false},
+			wantHealth: types.HealthUnhealthy,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.identifier, func(t *testing.T) {
+			status, err := GetWebAppStatus(client, test.identifier)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(status, test.want) {
+				t.Errorf("got %+v, want %+v", status, test.want)
+			}
+
+			if health := status.GetHealth(); health.State != test.wantHealth {
+				t.Errorf("got health %s (%s), want %s", health.State, health.Reason, test.wantHealth)
+			}
+		})
+	}
+}
+
+func TestGetWebAppStatusErrors(t *testing.T) {
+	client := newFakeARM(t)
+
+	tests := []struct {
+		identifier string
+		want       string
+	}{
+		{"shop-rg/locked", "azure api returned 403: AuthorizationFailed: The client does not have authorization"},
+		{"shop-rg/flaky", "azure api returned 502: <html>bad gateway</html>"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.identifier, func(t *testing.T) {
+			_, err := GetWebAppStatus(client, test.identifier)
+			if err == nil || err.Error() != test.want {
+				t.Errorf("got error %v, want %q", err, test.want)
+			}
+		})
+	}
+}
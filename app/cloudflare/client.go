@@ -0,0 +1,156 @@
+package cloudflare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hermes/app/types"
+	"os"
+
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/accounts"
+	"github.com/cloudflare/cloudflare-go/v4/option"
+)
+
+// DefaultAccountName is the account used by resources that don't reference
+// one. Unless it is defined in the config, it is built from the CLOUDFLARE_*
+// environment variables
+const DefaultAccountName = "default"
+
+// Account pairs an authenticated client with the account it acts on
+type Account struct {
+	Client    *cloudflare.Client
+	AccountID string
+
+	usesToken bool
+}
+
+// isNotFound reports whether err is a 404 returned by the cloudflare api
+func isNotFound(err error) bool {
+	var cloudflareErr *cloudflare.Error
+	return errors.As(err, &cloudflareErr) && cloudflareErr.StatusCode == 404
+}
+
+// the sdk always applies any credentials found in the environment, so the
+// headers for whichever auth scheme isn't in use are stripped explicitly
+func tokenAuthOptions(token string) []option.RequestOption {
+	return []option.RequestOption{
+		option.WithAPIToken(token),
+		option.WithHeaderDel("X-Auth-Key"),
+		option.WithHeaderDel("X-Auth-Email"),
+		option.WithHeaderDel("X-Auth-User-Service-Key"),
+	}
+}
+
+func keyAuthOptions(key string, email string) []option.RequestOption {
+	return []option.RequestOption{
+		option.WithAPIKey(key),
+		option.WithAPIEmail(email),
+		option.WithHeaderDel("Authorization"),
+	}
+}
+
+// NewAccount builds an account from its definition in the config, preferring
+// a scoped api token over a global api key when both are given
+func NewAccount(definition types.CloudflareAccountDefinition) (Account, error) {
+	if definition.AccountID == "" {
+		return Account{}, fmt.Errorf("cloudflare account %s has no account id", definition.Name)
+	}
+
+	if definition.APITokenEnv != "" {
+		token := os.Getenv(definition.APITokenEnv)
+		if token == "" {
+			return Account{}, fmt.Errorf("%s not found", definition.APITokenEnv)
+		}
+
+		return Account{
+			Client:    cloudflare.NewClient(tokenAuthOptions(token)...),
+			AccountID: definition.AccountID,
+			usesToken: true,
+		}, nil
+	}
+
+	if definition.APIKeyEnv != "" && definition.EmailEnv != "" {
+		key := os.Getenv(definition.APIKeyEnv)
+		email := os.Getenv(definition.EmailEnv)
+		if key == "" || email == "" {
+			return Account{}, fmt.Errorf("%s or %s not found", definition.APIKeyEnv, definition.EmailEnv)
+		}
+
+		return Account{
+			Client:    cloudflare.NewClient(keyAuthOptions(key, email)...),
+			AccountID: definition.AccountID,
+			usesToken: false,
+		}, nil
+	}
+
+	return Account{}, fmt.Errorf("cloudflare account %s has no credentials configured", definition.Name)
+}
+
+// NewDefaultAccount builds an account from CLOUDFLARE_ACCOUNT_ID and either
+// CLOUDFLARE_API_TOKEN or CLOUDFLARE_EMAIL and CLOUDFLARE_API_KEY
+func NewDefaultAccount() (Account, error) {
+	definition := types.CloudflareAccountDefinition{
+		Name:      DefaultAccountName,
+		AccountID: os.Getenv("CLOUDFLARE_ACCOUNT_ID"),
+		APIKeyEnv: "CLOUDFLARE_API_KEY",
+		EmailEnv:  "CLOUDFLARE_EMAIL",
+	}
+
+	if _, found := os.LookupEnv("CLOUDFLARE_API_TOKEN"); found {
+		definition.APITokenEnv = "CLOUDFLARE_API_TOKEN"
+	}
+
+	return NewAccount(definition)
+}
+
+// DefaultAccountEnvVars lists the environment variables the default account
+// needs, depending on whether token or key auth is in use
+func DefaultAccountEnvVars() []string {
+	if _, found := os.LookupEnv("CLOUDFLARE_API_TOKEN"); found {
+		return []string{"CLOUDFLARE_API_TOKEN", "CLOUDFLARE_ACCOUNT_ID"}
+	}
+
+	return []string{"CLOUDFLARE_EMAIL", "CLOUDFLARE_API_KEY", "CLOUDFLARE_ACCOUNT_ID"}
+}
+
+// VerifyAccount makes a lightweight call to check that the account's
+// credentials are valid
+func VerifyAccount(account Account) error {
+	if !account.usesToken {
+		_, err := account.Client.Accounts.Get(
+			context.TODO(),
+			accounts.AccountGetParams{
+				AccountID: cloudflare.F(account.AccountID),
+			},
+		)
+
+		return err
+	}
+
+	userToken, err := account.Client.User.Tokens.Verify(context.TODO())
+	if err == nil {
+		return checkTokenStatus(string(userToken.Status))
+	}
+
+	// account owned tokens can only be verified against their own account
+	accountToken, accountErr := account.Client.Accounts.Tokens.Verify(
+		context.TODO(),
+		accounts.TokenVerifyParams{
+			AccountID: cloudflare.F(account.AccountID),
+		},
+	)
+	if accountErr == nil {
+		return checkTokenStatus(string(accountToken.Status))
+	}
+
+	return err
+}
+
+func checkTokenStatus(status string) error {
+	if status != "active" {
+		return fmt.Errorf("api token is %s", status)
+	}
+
+	return nil
+}
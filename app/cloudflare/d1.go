@@ -0,0 +1,97 @@
+package cloudflare
+
+import (
+	"context"
+	"hermes/app/types"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/d1"
+)
+
+var _ types.ResourceStatus = D1Status{}
+
+type D1Status struct {
+	InstanceExists bool      `json:"exists"`
+	DatabaseId     string    `json:"database_id"`
+	Version        string    `json:"version"`
+	SizeBytes      int64     `json:"size_bytes"`
+	NumTables      int       `json:"num_tables"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (d D1Status) IsResourceStatus() {}
+
+func (d D1Status) GetHealth() types.Health {
+	if !d.InstanceExists {
+		return types.Unhealthy("not found")
+	}
+
+	return types.Healthy()
+}
+
+func (d D1Status) Exists() bool {
+	return d.InstanceExists
+}
+
+func (d D1Status) GetStatusString() string {
+	return "active"
+}
+
+// findD1Database returns the ID of the D1 database whose ID or name matches
+// the given identifier
+func findD1Database(client *cloudflare.Client, accountId string, databaseIdentifier string) (string, bool, error) {
+	pager := client.D1.Database.ListAutoPaging(
+		context.TODO(),
+		d1.DatabaseListParams{
+			AccountID: cloudflare.F(accountId),
+		},
+	)
+
+	for pager.Next() {
+		database := pager.Current()
+		if database.UUID == databaseIdentifier || database.Name == databaseIdentifier {
+			return database.UUID, true, nil
+		}
+	}
+
+	if err := pager.Err(); err != nil {
+		return "", false, err
+	}
+
+	return "", false, nil
+}
+
+func GetD1Status(account Account, databaseIdentifier string) (D1Status, error) {
+	databaseId, found, err := findD1Database(account.Client, account.AccountID, databaseIdentifier)
+	if err != nil {
+		return D1Status{}, err
+	}
+
+	if !found {
+		return D1Status{
+			InstanceExists: false,
+		}, nil
+	}
+
+	database, err := account.Client.D1.Database.Get(
+		context.TODO(),
+		databaseId,
+		d1.DatabaseGetParams{
+			AccountID: cloudflare.F(account.AccountID),
+		},
+	)
+
+	if err != nil {
+		return D1Status{}, err
+	}
+
+	return D1Status{
+		InstanceExists: true,
+		DatabaseId:     database.UUID,
+		Version:        database.Version,
+		SizeBytes:      int64(database.FileSize),
+		NumTables:      int(database.NumTables),
+		CreatedAt:      database.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,189 @@
+package cloudflare
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hermes/app/types"
+	"path"
+	"strconv"
+
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/d1"
+	"github.com/cloudflare/cloudflare-go/v4/kv"
+	"github.com/cloudflare/cloudflare-go/v4/option"
+	"github.com/cloudflare/cloudflare-go/v4/pages"
+	"github.com/cloudflare/cloudflare-go/v4/workers"
+	"github.com/cloudflare/cloudflare-go/v4/zones"
+)
+
+const pagesProjectsPerPage = 10
+
+// lists the names of every resource of a type in an account
+var discoveryListers = map[types.ResourceType]func(Account) ([]string, error){
+	types.CloudflarePagesResource:   listPagesProjects,
+	types.CloudflareWorkersResource: listWorkersScripts,
+	types.CloudflareZoneResource:    listZones,
+	types.CloudflareKVResource:      listKVNamespaces,
+	types.CloudflareD1Resource:      listD1Databases,
+}
+
+// IsDiscoverable reports whether resources of the given type can be found
+// by listing the account
+func IsDiscoverable(resourceType types.ResourceType) bool {
+	_, found := discoveryListers[resourceType]
+	return found
+}
+
+func listPagesProjects(account Account) ([]string, error) {
+	// as with deployments, the sdk models this endpoint as a single page,
+	// and its items as deployments rather than projects
+	names := []string{}
+	for page := 1; ; page++ {
+		resp, err := account.Client.Pages.Projects.List(
+			context.TODO(),
+			pages.ProjectListParams{
+				AccountID: cloudflare.F(account.AccountID),
+			},
+			option.WithQuery("page", strconv.Itoa(page)),
+			option.WithQuery("per_page", strconv.Itoa(pagesProjectsPerPage)),
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range resp.Result {
+			var project struct {
+				Name string `json:"name"`
+			}
+
+			err = json.Unmarshal([]byte(item.JSON.RawJSON()), &project)
+			if err != nil {
+				return nil, err
+			}
+
+			names = append(names, project.Name)
+		}
+
+		if len(resp.Result) < pagesProjectsPerPage {
+			return names, nil
+		}
+	}
+}
+
+func listWorkersScripts(account Account) ([]string, error) {
+	scripts, err := account.Client.Workers.Scripts.List(
+		context.TODO(),
+		workers.ScriptListParams{
+			AccountID: cloudflare.F(account.AccountID),
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, script := range scripts.Result {
+		names = append(names, script.ID)
+	}
+
+	return names, nil
+}
+
+func listZones(account Account) ([]string, error) {
+	pager := account.Client.Zones.ListAutoPaging(
+		context.TODO(),
+		zones.ZoneListParams{
+			Account: cloudflare.F(zones.ZoneListParamsAccount{
+				ID: cloudflare.F(account.AccountID),
+			}),
+		},
+	)
+
+	names := []string{}
+	for pager.Next() {
+		names = append(names, pager.Current().Name)
+	}
+
+	if err := pager.Err(); err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+func listKVNamespaces(account Account) ([]string, error) {
+	pager := account.Client.KV.Namespaces.ListAutoPaging(
+		context.TODO(),
+		kv.NamespaceListParams{
+			AccountID: cloudflare.F(account.AccountID),
+		},
+	)
+
+	names := []string{}
+	for pager.Next() {
+		names = append(names, pager.Current().Title)
+	}
+
+	if err := pager.Err(); err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+func listD1Databases(account Account) ([]string, error) {
+	pager := account.Client.D1.Database.ListAutoPaging(
+		context.TODO(),
+		d1.DatabaseListParams{
+			AccountID: cloudflare.F(account.AccountID),
+		},
+	)
+
+	names := []string{}
+	for pager.Next() {
+		names = append(names, pager.Current().Name)
+	}
+
+	if err := pager.Err(); err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// DiscoverResources lists resources of the given types in the account whose
+// names match pattern, a glob as accepted by path.Match
+func DiscoverResources(account Account, pattern string, resourceTypes []types.ResourceType) ([]types.ResourceDefinition, error) {
+	resources := []types.ResourceDefinition{}
+	for _, resourceType := range resourceTypes {
+		lister, found := discoveryListers[resourceType]
+		if !found {
+			return nil, fmt.Errorf("resource type can't be discovered by listing: %s", resourceType)
+		}
+
+		names, err := lister(account)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range names {
+			matched, err := path.Match(pattern, name)
+			if err != nil {
+				return nil, err
+			}
+
+			if matched {
+				resources = append(resources, types.ResourceDefinition{
+					Name:       name,
+					Identifier: name,
+					Type:       resourceType,
+				})
+			}
+		}
+	}
+
+	return resources, nil
+}
@@ -0,0 +1,155 @@
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"hermes/app/types"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/dns"
+)
+
+var _ types.ResourceStatus = DNSRecordStatus{}
+
+type DNSRecordStatus struct {
+	InstanceExists bool        `json:"exists"`
+	Records        []DNSRecord `json:"records"`
+	Mismatches     []string    `json:"mismatches"`
+}
+
+func (d DNSRecordStatus) IsResourceStatus() {}
+
+func (d DNSRecordStatus) GetHealth() types.Health {
+	if len(d.Mismatches) > 0 {
+		return types.Unhealthy(strings.Join(d.Mismatches, ", "))
+	}
+
+	return types.Healthy()
+}
+
+func (d DNSRecordStatus) Exists() bool {
+	return d.InstanceExists
+}
+
+func (d DNSRecordStatus) GetStatusString() string {
+	if len(d.Mismatches) > 0 {
+		return "mismatch"
+	}
+
+	return "ok"
+}
+
+type DNSRecord struct {
+	Type    string  `json:"type"`
+	Content string  `json:"content"`
+	Proxied bool    `json:"proxied"`
+	TTL     float64 `json:"ttl"`
+}
+
+func normalizeRecordContent(content string) string {
+	return strings.TrimSuffix(strings.ToLower(content), ".")
+}
+
+// findRecordMismatches compares the records found under a name against the
+// expectations declared for it
+func findRecordMismatches(records []DNSRecord, options types.DNSRecordOptions) []string {
+	mismatches := []string{}
+
+	candidates := records
+	if options.Content != "" {
+		candidates = []DNSRecord{}
+		for _, record := range records {
+			if normalizeRecordContent(record.Content) == normalizeRecordContent(options.Content) {
+				candidates = append(candidates, record)
+			}
+		}
+
+		if len(candidates) == 0 {
+			found := []string{}
+			for _, record := range records {
+				found = append(found, record.Content)
+			}
+
+			mismatches = append(mismatches,
+				fmt.Sprintf("content: expected %s, found %v", options.Content, found),
+			)
+		}
+	}
+
+	if options.Proxied != nil {
+		for _, record := range candidates {
+			if record.Proxied != *options.Proxied {
+				mismatches = append(mismatches,
+					fmt.Sprintf("proxied: expected %t for %s, found %t", *options.Proxied, record.Content, record.Proxied),
+				)
+			}
+		}
+	}
+
+	return mismatches
+}
+
+// GetDNSRecordStatus checks the records under recordName in the zone named by
+// the resource's options, optionally narrowed to a single record type
+func GetDNSRecordStatus(account Account, recordName string, options *types.DNSRecordOptions) (DNSRecordStatus, error) {
+	if options == nil || options.Zone == "" {
+		return DNSRecordStatus{}, fmt.Errorf("dns record %s has no zone configured", recordName)
+	}
+
+	zone, found, err := findZone(account.Client, account.AccountID, options.Zone)
+	if err != nil {
+		return DNSRecordStatus{}, err
+	}
+
+	if !found {
+		return DNSRecordStatus{
+			InstanceExists: false,
+			Mismatches:     []string{fmt.Sprintf("zone %s not found", options.Zone)},
+		}, nil
+	}
+
+	params := dns.RecordListParams{
+		ZoneID: cloudflare.F(zone.ID),
+		Name: cloudflare.F(dns.RecordListParamsName{
+			Exact: cloudflare.F(recordName),
+		}),
+	}
+
+	if options.Type != "" {
+		params.Type = cloudflare.F(dns.RecordListParamsType(strings.ToUpper(options.Type)))
+	}
+
+	pager := account.Client.DNS.Records.ListAutoPaging(context.TODO(), params)
+
+	records := []DNSRecord{}
+	for pager.Next() {
+		record := pager.Current()
+		records = append(records,
+			DNSRecord{
+				Type:    string(record.Type),
+				Content: record.Content,
+				Proxied: record.Proxied,
+				TTL:     float64(record.TTL),
+			},
+		)
+	}
+
+	if err := pager.Err(); err != nil {
+		return DNSRecordStatus{}, err
+	}
+
+	if len(records) == 0 {
+		return DNSRecordStatus{
+			InstanceExists: false,
+			Records:        records,
+			Mismatches:     []string{"no matching records"},
+		}, nil
+	}
+
+	return DNSRecordStatus{
+		InstanceExists: true,
+		Records:        records,
+		Mismatches:     findRecordMismatches(records, *options),
+	}, nil
+}
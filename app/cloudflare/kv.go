@@ -0,0 +1,64 @@
+package cloudflare
+
+import (
+	"context"
+	"hermes/app/types"
+
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/kv"
+)
+
+var _ types.ResourceStatus = KVStatus{}
+
+type KVStatus struct {
+	InstanceExists bool   `json:"exists"`
+	NamespaceId    string `json:"namespace_id"`
+	Title          string `json:"title"`
+}
+
+func (k KVStatus) IsResourceStatus() {}
+
+func (k KVStatus) GetHealth() types.Health {
+	if !k.InstanceExists {
+		return types.Unhealthy("not found")
+	}
+
+	return types.Healthy()
+}
+
+func (k KVStatus) Exists() bool {
+	return k.InstanceExists
+}
+
+func (k KVStatus) GetStatusString() string {
+	return "active"
+}
+
+// GetKVStatus looks up a workers kv namespace by its ID or title
+func GetKVStatus(account Account, namespaceIdentifier string) (KVStatus, error) {
+	pager := account.Client.KV.Namespaces.ListAutoPaging(
+		context.TODO(),
+		kv.NamespaceListParams{
+			AccountID: cloudflare.F(account.AccountID),
+		},
+	)
+
+	for pager.Next() {
+		namespace := pager.Current()
+		if namespace.ID == namespaceIdentifier || namespace.Title == namespaceIdentifier {
+			return KVStatus{
+				InstanceExists: true,
+				NamespaceId:    namespace.ID,
+				Title:          namespace.Title,
+			}, nil
+		}
+	}
+
+	if err := pager.Err(); err != nil {
+		return KVStatus{}, err
+	}
+
+	return KVStatus{
+		InstanceExists: false,
+	}, nil
+}
@@ -0,0 +1,255 @@
+package cloudflare
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hermes/app/types"
+	"slices"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/load_balancers"
+)
+
+var _ types.ResourceStatus = LoadBalancerStatus{}
+
+type LoadBalancerStatus struct {
+	InstanceExists bool               `json:"exists"`
+	Enabled        bool               `json:"enabled"`
+	Pools          []LoadBalancerPool `json:"pools"`
+}
+
+func (l LoadBalancerStatus) IsResourceStatus() {}
+
+func (l LoadBalancerStatus) GetHealth() types.Health {
+	if !l.InstanceExists {
+		return types.Unhealthy("not found")
+	}
+
+	if !l.Enabled {
+		return types.Unhealthy("load balancer is disabled")
+	}
+
+	unhealthyPools := []string{}
+	for _, pool := range l.Pools {
+		if !pool.Healthy {
+			unhealthyPools = append(unhealthyPools, pool.Name)
+		}
+	}
+
+	if len(unhealthyPools) == len(l.Pools) {
+		return types.Unhealthy("no healthy pools")
+	}
+
+	if len(unhealthyPools) > 0 {
+		return types.Degraded(fmt.Sprintf("unhealthy pools: %s", strings.Join(unhealthyPools, ", ")))
+	}
+
+	return types.Healthy()
+}
+
+func (l LoadBalancerStatus) Exists() bool {
+	return l.InstanceExists
+}
+
+func (l LoadBalancerStatus) GetStatusString() string {
+	if !l.Enabled {
+		return "disabled"
+	}
+
+	healthyPools := 0
+	for _, pool := range l.Pools {
+		if pool.Healthy {
+			healthyPools += 1
+		}
+	}
+
+	if healthyPools == 0 {
+		return "down"
+	}
+
+	if healthyPools < len(l.Pools) {
+		return "degraded"
+	}
+
+	return "healthy"
+}
+
+type LoadBalancerPool struct {
+	ID       string               `json:"id"`
+	Name     string               `json:"name"`
+	Enabled  bool                 `json:"enabled"`
+	Fallback bool                 `json:"fallback"`
+	Healthy  bool                 `json:"healthy"`
+	Origins  []LoadBalancerOrigin `json:"origins"`
+}
+
+type LoadBalancerOrigin struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	Enabled bool   `json:"enabled"`
+	// how many of the pool's monitoring regions currently see this origin
+	// as healthy
+	HealthyChecks int    `json:"healthy_checks"`
+	TotalChecks   int    `json:"total_checks"`
+	FailureReason string `json:"failure_reason,omitempty"`
+}
+
+// poolHealthResponse mirrors the pool health endpoint, which the sdk models
+// with a single pop rather than a map keyed by pop name
+type poolHealthResponse struct {
+	POPHealth map[string]struct {
+		Healthy bool `json:"healthy"`
+		Origins []map[string]struct {
+			Healthy       bool   `json:"healthy"`
+			FailureReason string `json:"failure_reason"`
+		} `json:"origins"`
+	} `json:"pop_health"`
+}
+
+func getLoadBalancerPool(client *cloudflare.Client, accountId string, poolId string) (LoadBalancerPool, error) {
+	pool, err := client.LoadBalancers.Pools.Get(
+		context.TODO(),
+		poolId,
+		load_balancers.PoolGetParams{
+			AccountID: cloudflare.F(accountId),
+		},
+	)
+
+	if err != nil {
+		return LoadBalancerPool{}, err
+	}
+
+	healthResp, err := client.LoadBalancers.Pools.Health.Get(
+		context.TODO(),
+		poolId,
+		load_balancers.PoolHealthGetParams{
+			AccountID: cloudflare.F(accountId),
+		},
+	)
+
+	if err != nil {
+		return LoadBalancerPool{}, err
+	}
+
+	var health poolHealthResponse
+	err = json.Unmarshal([]byte(healthResp.JSON.RawJSON()), &health)
+	if err != nil {
+		return LoadBalancerPool{}, err
+	}
+
+	origins := []LoadBalancerOrigin{}
+	for _, origin := range pool.Origins {
+		origins = append(origins,
+			LoadBalancerOrigin{
+				Name:    origin.Name,
+				Address: origin.Address,
+				Enabled: origin.Enabled,
+			},
+		)
+	}
+
+	poolHealthy := pool.Enabled && len(health.POPHealth) > 0
+	for _, pop := range health.POPHealth {
+		poolHealthy = poolHealthy && pop.Healthy
+
+		for _, popOrigins := range pop.Origins {
+			for address, originHealth := range popOrigins {
+				originIdx := slices.IndexFunc(
+					origins,
+					func(o LoadBalancerOrigin) bool { return o.Address == address },
+				)
+				if originIdx == -1 {
+					continue
+				}
+
+				origins[originIdx].TotalChecks += 1
+				if originHealth.Healthy {
+					origins[originIdx].HealthyChecks += 1
+				} else if originHealth.FailureReason != "" {
+					origins[originIdx].FailureReason = originHealth.FailureReason
+				}
+			}
+		}
+	}
+
+	return LoadBalancerPool{
+		ID:      pool.ID,
+		Name:    pool.Name,
+		Enabled: pool.Enabled,
+		Healthy: poolHealthy,
+		Origins: origins,
+	}, nil
+}
+
+// GetLoadBalancerStatus reports the health of a load balancer's default and
+// fallback pools as seen by cloudflare's monitors. Load balancers are zone
+// scoped, so the zone is taken from the resource's options
+func GetLoadBalancerStatus(
+	account Account,
+	loadBalancerIdentifier string,
+	options *types.LoadBalancerOptions,
+) (LoadBalancerStatus, error) {
+	if options == nil || options.Zone == "" {
+		return LoadBalancerStatus{}, fmt.Errorf("load balancer %s has no zone configured", loadBalancerIdentifier)
+	}
+
+	zone, found, err := findZone(account.Client, account.AccountID, options.Zone)
+	if err != nil {
+		return LoadBalancerStatus{}, err
+	}
+
+	if !found {
+		return LoadBalancerStatus{
+			InstanceExists: false,
+		}, nil
+	}
+
+	loadBalancers, err := account.Client.LoadBalancers.List(
+		context.TODO(),
+		load_balancers.LoadBalancerListParams{
+			ZoneID: cloudflare.F(zone.ID),
+		},
+	)
+
+	if err != nil {
+		return LoadBalancerStatus{}, err
+	}
+
+	loadBalancerIdx := slices.IndexFunc(
+		loadBalancers.Result,
+		func(l load_balancers.LoadBalancer) bool {
+			return l.ID == loadBalancerIdentifier || l.Name == loadBalancerIdentifier
+		},
+	)
+	if loadBalancerIdx == -1 {
+		return LoadBalancerStatus{
+			InstanceExists: false,
+		}, nil
+	}
+
+	loadBalancer := loadBalancers.Result[loadBalancerIdx]
+
+	poolIds := slices.Clone(loadBalancer.DefaultPools)
+	if loadBalancer.FallbackPool != "" && !slices.Contains(poolIds, loadBalancer.FallbackPool) {
+		poolIds = append(poolIds, loadBalancer.FallbackPool)
+	}
+
+	pools := []LoadBalancerPool{}
+	for _, poolId := range poolIds {
+		pool, err := getLoadBalancerPool(account.Client, account.AccountID, poolId)
+		if err != nil {
+			return LoadBalancerStatus{}, err
+		}
+
+		pool.Fallback = poolId == loadBalancer.FallbackPool && !slices.Contains(loadBalancer.DefaultPools, poolId)
+		pools = append(pools, pool)
+	}
+
+	return LoadBalancerStatus{
+		InstanceExists: true,
+		Enabled:        loadBalancer.Enabled,
+		Pools:          pools,
+	}, nil
+}
@@ -2,27 +2,53 @@ package cloudflare
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"hermes/app/types"
-	"os"
+	"strconv"
+	"time"
 
 	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/option"
 	"github.com/cloudflare/cloudflare-go/v4/pages"
 )
 
+// the deployments endpoint caps page size at 25
+const pagesDeploymentsPerPage = 25
+
 var _ types.ResourceStatus = PagesStatus{}
 
 type PagesStatus struct {
 	InstanceExists            bool   `json:"exists"`
 	CanonicalDeploymentStatus string `json:"status"`
 	CanonicalDeploymentUrl    string `json:"url"`
+	ProductionBranch          string `json:"production_branch"`
+	// the newest successful production deployment, which pages keeps
+	// serving while a later one builds or after it fails. Only looked up
+	// when the canonical deployment isn't successful itself
+	LastSuccessfulDeploymentID string `json:"last_successful_deployment_id,omitempty"`
 }
 
 func (p PagesStatus) IsResourceStatus() {}
 
-func (p PagesStatus) IsHealthy() bool {
-	return p.CanonicalDeploymentStatus == "success"
+// GetHealth judges a project on its canonical deployment. While that one is
+// building ("active" or "idle") the project stays on an earlier successful
+// deployment, and is degraded rather than down when it failed, as long as
+// there is an earlier one to fall back to
+func (p PagesStatus) GetHealth() types.Health {
+	switch {
+	case !p.InstanceExists:
+		return types.Unhealthy("not found")
+	case p.CanonicalDeploymentStatus == "success":
+		return types.Healthy()
+	case p.CanonicalDeploymentStatus == "":
+		return types.Unhealthy("no production deployment")
+	case p.LastSuccessfulDeploymentID == "":
+		return types.Unhealthy(fmt.Sprintf("no successful production deployment, latest is %s", p.CanonicalDeploymentStatus))
+	case p.CanonicalDeploymentStatus == "active", p.CanonicalDeploymentStatus == "idle":
+		return types.Healthy()
+	default:
+		return types.Degraded(fmt.Sprintf("latest production deployment is %s", p.CanonicalDeploymentStatus))
+	}
 }
 
 func (p PagesStatus) Exists() bool {
@@ -33,58 +59,203 @@ func (p PagesStatus) GetStatusString() string {
 	return p.CanonicalDeploymentStatus
 }
 
-func GetPagesStatus(client *cloudflare.Client, projectName string) (PagesStatus, error) {
-	accountId, found := os.LookupEnv("CLOUDFLARE_ACCOUNT_ID")
-
-	if !found {
-		return PagesStatus{}, fmt.Errorf("CLOUDFLARE_ACCOUNT_ID not found")
-	}
-
-	project, err := client.Pages.Projects.Get(
+func GetPagesStatus(account Account, projectName string) (PagesStatus, error) {
+	project, err := account.Client.Pages.Projects.Get(
 		context.TODO(),
 		projectName,
 		pages.ProjectGetParams{
-			AccountID: cloudflare.F(accountId),
+			AccountID: cloudflare.F(account.AccountID),
 		},
 	)
 
 	if err != nil {
-		// if errors.As(err, )
-		var cloudflareErr *cloudflare.Error
-		if errors.As(err, &cloudflareErr) {
-			if cloudflareErr.StatusCode == 404 {
-				return PagesStatus{
-					InstanceExists: false,
-				}, nil
-			}
+		if isNotFound(err) {
+			return PagesStatus{
+				InstanceExists: false,
+			}, nil
 		}
 
 		return PagesStatus{}, err
 	}
 
-	// TODO: specific deployments + pagination
-	// deployments, err := client.Pages.Projects.Deployments.List(
-	// 	context.TODO(),
-	// 	projectName, pages.ProjectDeploymentListParams{
-	// 		AccountID: cloudflare.F(accountId),
-	// 	},
-	// )
-
-	// if err != nil {
-	// 	return CloudflarePagesDetails{}, nil
-	// }
-
-	// j, _ := json.MarshalIndent(deployments, "", "  ")
-	// fmt.Println(string(j))
-
-	return PagesStatus{
+	status := PagesStatus{
 		InstanceExists:            true,
 		CanonicalDeploymentStatus: project.CanonicalDeployment.LatestStage.Status,
 		CanonicalDeploymentUrl:    project.CanonicalDeployment.URL,
-	}, nil
+		ProductionBranch:          project.ProductionBranch,
+	}
+
+	if status.CanonicalDeploymentStatus != "" && status.CanonicalDeploymentStatus != "success" {
+		status.LastSuccessfulDeploymentID, err = findLastSuccessfulDeployment(account, projectName)
+		if err != nil {
+			return PagesStatus{}, err
+		}
+	}
+
+	return status, nil
+}
+
+// findLastSuccessfulDeployment returns the ID of the newest successful
+// production deployment among the most recent page of them, or an empty
+// string when there is none
+func findLastSuccessfulDeployment(account Account, projectName string) (string, error) {
+	resp, err := account.Client.Pages.Projects.Deployments.List(
+		context.TODO(),
+		projectName,
+		pages.ProjectDeploymentListParams{
+			AccountID: cloudflare.F(account.AccountID),
+			Env:       cloudflare.F(pages.ProjectDeploymentListParamsEnvProduction),
+		},
+		option.WithQuery("per_page", strconv.Itoa(pagesDeploymentsPerPage)),
+	)
+
+	if err != nil {
+		return "", err
+	}
+
+	for _, deployment := range resp.Result {
+		if deployment.LatestStage.Name == "deploy" && deployment.LatestStage.Status == "success" {
+			return deployment.ID, nil
+		}
+	}
+
+	return "", nil
+}
+
+var _ types.ResourceHistory = PagesHistory{}
+
+type PagesHistory struct {
+	Deployments []PagesDeployment `json:"deployments"`
+	// the oldest production deployment in the unbroken run of failures
+	// leading up to the latest one, nil when production is succeeding
+	ProductionFailingSince *PagesDeployment `json:"production_failing_since"`
 }
 
-func GetCloudflareClient() *cloudflare.Client {
-	// reads variables from environment by default
-	return cloudflare.NewClient()
+func (p PagesHistory) IsResourceHistory() {}
+
+type PagesDeployment struct {
+	ID            string        `json:"id"`
+	Environment   string        `json:"environment"`
+	Branch        string        `json:"branch"`
+	CommitHash    string        `json:"commit_hash"`
+	CommitMessage string        `json:"commit_message"`
+	URL           string        `json:"url"`
+	CreatedOn     time.Time     `json:"created_on"`
+	Stage         string        `json:"stage"`
+	Status        string        `json:"status"`
+	Duration      time.Duration `json:"duration"`
+	Stages        []PagesStage  `json:"stages"`
+}
+
+type PagesStage struct {
+	Name      string        `json:"name"`
+	Status    string        `json:"status"`
+	StartedOn time.Time     `json:"started_on"`
+	EndedOn   time.Time     `json:"ended_on"`
+	Duration  time.Duration `json:"duration"`
+}
+
+func (p PagesDeployment) failed() bool {
+	return p.Status == "failure"
+}
+
+func newPagesDeployment(deployment pages.Deployment) PagesDeployment {
+	stages := []PagesStage{}
+	var startedOn, endedOn time.Time
+	for _, stage := range deployment.Stages {
+		var duration time.Duration
+		if !stage.StartedOn.IsZero() && !stage.EndedOn.IsZero() {
+			duration = stage.EndedOn.Sub(stage.StartedOn)
+		}
+
+		if startedOn.IsZero() && !stage.StartedOn.IsZero() {
+			startedOn = stage.StartedOn
+		}
+
+		if !stage.EndedOn.IsZero() {
+			endedOn = stage.EndedOn
+		}
+
+		stages = append(stages,
+			PagesStage{
+				Name:      stage.Name,
+				Status:    stage.Status,
+				StartedOn: stage.StartedOn,
+				EndedOn:   stage.EndedOn,
+				Duration:  duration,
+			},
+		)
+	}
+
+	var duration time.Duration
+	if !startedOn.IsZero() && endedOn.After(startedOn) {
+		duration = endedOn.Sub(startedOn)
+	}
+
+	return PagesDeployment{
+		ID:            deployment.ID,
+		Environment:   deployment.Environment,
+		Branch:        deployment.DeploymentTrigger.Metadata.Branch,
+		CommitHash:    deployment.DeploymentTrigger.Metadata.CommitHash,
+		CommitMessage: deployment.DeploymentTrigger.Metadata.CommitMessage,
+		URL:           deployment.URL,
+		CreatedOn:     deployment.CreatedOn,
+		Stage:         deployment.LatestStage.Name,
+		Status:        deployment.LatestStage.Status,
+		Duration:      duration,
+		Stages:        stages,
+	}
+}
+
+// GetPagesHistory lists up to limit of the project's most recent production
+// and preview deployments, newest first
+func GetPagesHistory(account Account, projectName string, limit int) (PagesHistory, error) {
+	// the sdk models this endpoint as a single page, so pages are requested
+	// explicitly until a short one comes back
+	deployments := []PagesDeployment{}
+	for page := 1; len(deployments) < limit; page++ {
+		resp, err := account.Client.Pages.Projects.Deployments.List(
+			context.TODO(),
+			projectName,
+			pages.ProjectDeploymentListParams{
+				AccountID: cloudflare.F(account.AccountID),
+			},
+			option.WithQuery("page", strconv.Itoa(page)),
+			option.WithQuery("per_page", strconv.Itoa(pagesDeploymentsPerPage)),
+		)
+
+		if err != nil {
+			return PagesHistory{}, err
+		}
+
+		for _, deployment := range resp.Result {
+			deployments = append(deployments, newPagesDeployment(deployment))
+		}
+
+		if len(resp.Result) < pagesDeploymentsPerPage {
+			break
+		}
+	}
+
+	if len(deployments) > limit {
+		deployments = deployments[:limit]
+	}
+
+	var failingSince *PagesDeployment
+	for _, deployment := range deployments {
+		if deployment.Environment != "production" {
+			continue
+		}
+
+		if !deployment.failed() {
+			break
+		}
+
+		failingSince = &deployment
+	}
+
+	return PagesHistory{
+		Deployments:            deployments,
+		ProductionFailingSince: failingSince,
+	}, nil
 }
@@ -0,0 +1,42 @@
+package cloudflare
+
+import (
+	"hermes/app/types"
+	"testing"
+)
+
+func TestPagesStatusGetHealth(t *testing.T) {
+	tests := []struct {
+		name   string
+		status PagesStatus
+		want   types.HealthState
+	}{
+		{"not found", PagesStatus{}, types.HealthUnhealthy},
+		{"success", PagesStatus{InstanceExists: true, CanonicalDeploymentStatus: "success"}, types.HealthHealthy},
+		{"no deployments", PagesStatus{InstanceExists: true}, types.HealthUnhealthy},
+		{
+			"first deployment building",
+			PagesStatus{InstanceExists: true, CanonicalDeploymentStatus: "active"},
+			types.HealthUnhealthy,
+		},
+		{
+			"building over a successful deployment",
+			PagesStatus{InstanceExists: true, CanonicalDeploymentStatus: "active", LastSuccessfulDeploymentID: "abc"},
+			types.HealthHealthy,
+		},
+		{
+			"failed over a successful deployment",
+			PagesStatus{InstanceExists: true, CanonicalDeploymentStatus: "failure", LastSuccessfulDeploymentID: "abc"},
+			types.HealthDegraded,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.status.GetHealth()
+			if got.State != test.want {
+				t.Errorf("got %s (%s), want %s", got.State, got.Reason, test.want)
+			}
+		})
+	}
+}
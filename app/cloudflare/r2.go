@@ -0,0 +1,158 @@
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"hermes/app/types"
+	"log"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/r2"
+)
+
+// storage metrics are only published periodically, so look back far enough
+// to be sure of finding a datapoint
+const r2MetricsWindow = 24 * time.Hour
+
+const r2StorageQuery = `query R2Storage($accountTag: string!, $bucket: string!, $start: Time!, $end: Time!) {
+  viewer {
+    accounts(filter: {accountTag: $accountTag}) {
+      r2StorageAdaptiveGroups(
+        limit: 1
+        filter: {bucketName: $bucket, datetime_geq: $start, datetime_leq: $end}
+        orderBy: [datetime_DESC]
+      ) {
+        max {
+          objectCount
+          payloadSize
+        }
+      }
+    }
+  }
+}`
+
+var _ types.ResourceStatus = R2Status{}
+
+type R2Status struct {
+	InstanceExists bool   `json:"exists"`
+	Location       string `json:"location"`
+	StorageClass   string `json:"storage_class"`
+	CreationDate   string `json:"creation_date"`
+	// object count and size come from the analytics api and are nil when it
+	// can't be queried
+	ObjectCount  *int64 `json:"object_count"`
+	PayloadBytes *int64 `json:"payload_bytes"`
+}
+
+func (r R2Status) IsResourceStatus() {}
+
+func (r R2Status) GetHealth() types.Health {
+	if !r.InstanceExists {
+		return types.Unhealthy("not found")
+	}
+
+	return types.Healthy()
+}
+
+func (r R2Status) Exists() bool {
+	return r.InstanceExists
+}
+
+func (r R2Status) GetStatusString() string {
+	return "active"
+}
+
+type r2StorageResponse struct {
+	Data struct {
+		Viewer struct {
+			Accounts []struct {
+				R2StorageAdaptiveGroups []struct {
+					Max struct {
+						ObjectCount int64 `json:"objectCount"`
+						PayloadSize int64 `json:"payloadSize"`
+					} `json:"max"`
+				} `json:"r2StorageAdaptiveGroups"`
+			} `json:"accounts"`
+		} `json:"viewer"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func getR2Storage(client *cloudflare.Client, accountId string, bucketName string) (int64, int64, bool, error) {
+	now := time.Now().UTC()
+
+	var resp r2StorageResponse
+	err := client.Post(
+		context.TODO(),
+		"graphql",
+		map[string]any{
+			"query": r2StorageQuery,
+			"variables": map[string]any{
+				"accountTag": accountId,
+				"bucket":     bucketName,
+				"start":      now.Add(-r2MetricsWindow).Format(time.RFC3339),
+				"end":        now.Format(time.RFC3339),
+			},
+		},
+		&resp,
+	)
+
+	if err != nil {
+		return 0, 0, false, err
+	}
+
+	if len(resp.Errors) > 0 {
+		return 0, 0, false, fmt.Errorf("r2 storage query failed: %s", resp.Errors[0].Message)
+	}
+
+	accounts := resp.Data.Viewer.Accounts
+	if len(accounts) == 0 || len(accounts[0].R2StorageAdaptiveGroups) == 0 {
+		return 0, 0, false, nil
+	}
+
+	storage := accounts[0].R2StorageAdaptiveGroups[0].Max
+
+	return storage.ObjectCount, storage.PayloadSize, true, nil
+}
+
+func GetR2Status(account Account, bucketName string) (R2Status, error) {
+	bucket, err := account.Client.R2.Buckets.Get(
+		context.TODO(),
+		bucketName,
+		r2.BucketGetParams{
+			AccountID: cloudflare.F(account.AccountID),
+		},
+	)
+
+	if err != nil {
+		if isNotFound(err) {
+			return R2Status{
+				InstanceExists: false,
+			}, nil
+		}
+
+		return R2Status{}, err
+	}
+
+	status := R2Status{
+		InstanceExists: true,
+		Location:       string(bucket.Location),
+		StorageClass:   string(bucket.StorageClass),
+		CreationDate:   bucket.CreationDate,
+	}
+
+	// analytics access is a separate token permission, so a failure here
+	// shouldn't fail the whole check
+	objectCount, payloadBytes, found, err := getR2Storage(account.Client, account.AccountID, bucketName)
+	if err != nil {
+		log.Println("error fetching r2 storage metrics", bucketName, err)
+	} else if found {
+		status.ObjectCount = &objectCount
+		status.PayloadBytes = &payloadBytes
+	}
+
+	return status, nil
+}
@@ -0,0 +1,134 @@
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"hermes/app/types"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/zero_trust"
+)
+
+var _ types.ResourceStatus = TunnelStatus{}
+
+type TunnelStatus struct {
+	InstanceExists   bool              `json:"exists"`
+	TunnelId         string            `json:"tunnel_id"`
+	Status           string            `json:"status"`
+	ActiveConnectors int               `json:"active_connectors"`
+	Connectors       []TunnelConnector `json:"connectors"`
+}
+
+func (t TunnelStatus) IsResourceStatus() {}
+
+func (t TunnelStatus) GetHealth() types.Health {
+	switch {
+	case !t.InstanceExists:
+		return types.Unhealthy("not found")
+	case t.Status == string(zero_trust.TunnelListResponseStatusHealthy):
+		return types.Healthy()
+	case t.Status == string(zero_trust.TunnelListResponseStatusDegraded):
+		return types.Degraded(fmt.Sprintf("%d active connectors", t.ActiveConnectors))
+	default:
+		return types.Unhealthy(fmt.Sprintf("tunnel is %s", t.Status))
+	}
+}
+
+func (t TunnelStatus) Exists() bool {
+	return t.InstanceExists
+}
+
+func (t TunnelStatus) GetStatusString() string {
+	return t.Status
+}
+
+type TunnelConnector struct {
+	ID          string    `json:"id"`
+	Version     string    `json:"version"`
+	Arch        string    `json:"arch"`
+	RunAt       time.Time `json:"run_at"`
+	Connections int       `json:"connections"`
+	Colos       []string  `json:"colos"`
+}
+
+// findTunnel looks up a non-deleted cloudflared tunnel by its ID or name
+func findTunnel(client *cloudflare.Client, accountId string, tunnelIdentifier string) (zero_trust.TunnelListResponse, bool, error) {
+	pager := client.ZeroTrust.Tunnels.ListAutoPaging(
+		context.TODO(),
+		zero_trust.TunnelListParams{
+			AccountID: cloudflare.F(accountId),
+			IsDeleted: cloudflare.F(false),
+		},
+	)
+
+	for pager.Next() {
+		tunnel := pager.Current()
+		if tunnel.ID == tunnelIdentifier || tunnel.Name == tunnelIdentifier {
+			return tunnel, true, nil
+		}
+	}
+
+	if err := pager.Err(); err != nil {
+		return zero_trust.TunnelListResponse{}, false, err
+	}
+
+	return zero_trust.TunnelListResponse{}, false, nil
+}
+
+func GetTunnelStatus(account Account, tunnelIdentifier string) (TunnelStatus, error) {
+	tunnel, found, err := findTunnel(account.Client, account.AccountID, tunnelIdentifier)
+	if err != nil {
+		return TunnelStatus{}, err
+	}
+
+	if !found {
+		return TunnelStatus{
+			InstanceExists: false,
+		}, nil
+	}
+
+	connectionsResp, err := account.Client.ZeroTrust.Tunnels.Connections.Get(
+		context.TODO(),
+		tunnel.ID,
+		zero_trust.TunnelConnectionGetParams{
+			AccountID: cloudflare.F(account.AccountID),
+		},
+	)
+
+	if err != nil {
+		return TunnelStatus{}, err
+	}
+
+	activeConnectors := 0
+	connectors := []TunnelConnector{}
+	for _, connector := range connectionsResp.Result {
+		colos := []string{}
+		for _, conn := range connector.Conns {
+			colos = append(colos, conn.ColoName)
+		}
+
+		if len(connector.Conns) > 0 {
+			activeConnectors += 1
+		}
+
+		connectors = append(connectors,
+			TunnelConnector{
+				ID:          connector.ID,
+				Version:     connector.Version,
+				Arch:        connector.Arch,
+				RunAt:       connector.RunAt,
+				Connections: len(connector.Conns),
+				Colos:       colos,
+			},
+		)
+	}
+
+	return TunnelStatus{
+		InstanceExists:   true,
+		TunnelId:         tunnel.ID,
+		Status:           string(tunnel.Status),
+		ActiveConnectors: activeConnectors,
+		Connectors:       connectors,
+	}, nil
+}
@@ -0,0 +1,295 @@
+package cloudflare
+
+import (
+	"context"
+	"encoding/json"
+	"hermes/app/types"
+	"slices"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/workers"
+)
+
+var _ types.ResourceStatus = WorkersStatus{}
+
+type WorkersStatus struct {
+	InstanceExists     bool               `json:"exists"`
+	ModifiedOn         time.Time          `json:"modified_on"`
+	LatestDeployment   *WorkersDeployment `json:"latest_deployment"`
+	CompatibilityDate  string             `json:"compatibility_date"`
+	CompatibilityFlags []string           `json:"compatibility_flags"`
+	Routes             []string           `json:"routes"`
+	CustomDomains      []string           `json:"custom_domains"`
+	Bindings           []WorkersBinding   `json:"bindings"`
+}
+
+func (w WorkersStatus) IsResourceStatus() {}
+
+func (w WorkersStatus) GetHealth() types.Health {
+	if !w.InstanceExists {
+		return types.Unhealthy("not found")
+	}
+
+	if w.LatestDeployment == nil {
+		return types.Unhealthy("no deployment")
+	}
+
+	return types.Healthy()
+}
+
+func (w WorkersStatus) Exists() bool {
+	return w.InstanceExists
+}
+
+func (w WorkersStatus) GetStatusString() string {
+	if w.LatestDeployment == nil {
+		return "undeployed"
+	}
+
+	return "deployed"
+}
+
+type WorkersDeployment struct {
+	ID        string                  `json:"id"`
+	CreatedOn string                  `json:"created_on"`
+	Source    string                  `json:"source"`
+	Author    string                  `json:"author"`
+	Message   string                  `json:"message"`
+	Versions  []WorkersVersionTraffic `json:"versions"`
+}
+
+type WorkersVersionTraffic struct {
+	VersionID  string  `json:"version_id"`
+	Percentage float64 `json:"percentage"`
+}
+
+type WorkersBinding struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// the bound kv namespace, r2 bucket, d1 database or queue, empty for
+	// binding types that don't reference another resource
+	Resource string `json:"resource"`
+}
+
+// workersVersionResources mirrors the parts of a version's untyped
+// "resources" object that hermes reports on
+type workersVersionResources struct {
+	Bindings      []map[string]any `json:"bindings"`
+	ScriptRuntime struct {
+		CompatibilityDate  string   `json:"compatibility_date"`
+		CompatibilityFlags []string `json:"compatibility_flags"`
+	} `json:"script_runtime"`
+}
+
+// the binding fields that hold the referenced resource, by binding type
+var workersBindingResourceFields = map[string]string{
+	"kv_namespace": "namespace_id",
+	"r2_bucket":    "bucket_name",
+	"d1":           "id",
+	"queue":        "queue_name",
+	"service":      "service",
+}
+
+func newWorkersBinding(binding map[string]any) WorkersBinding {
+	name, _ := binding["name"].(string)
+	bindingType, _ := binding["type"].(string)
+	resource, _ := binding[workersBindingResourceFields[bindingType]].(string)
+
+	return WorkersBinding{
+		Name:     name,
+		Type:     bindingType,
+		Resource: resource,
+	}
+}
+
+func getWorkersVersionResources(
+	client *cloudflare.Client,
+	accountId string,
+	scriptName string,
+	versionId string,
+) (workersVersionResources, error) {
+	version, err := client.Workers.Scripts.Versions.Get(
+		context.TODO(),
+		scriptName,
+		versionId,
+		workers.ScriptVersionGetParams{
+			AccountID: cloudflare.F(accountId),
+		},
+	)
+
+	if err != nil {
+		return workersVersionResources{}, err
+	}
+
+	// the sdk leaves resources untyped, so round trip it through json
+	raw, err := json.Marshal(version.Resources)
+	if err != nil {
+		return workersVersionResources{}, err
+	}
+
+	var resources workersVersionResources
+	err = json.Unmarshal(raw, &resources)
+	if err != nil {
+		return workersVersionResources{}, err
+	}
+
+	return resources, nil
+}
+
+func getWorkersRoutes(client *cloudflare.Client, scriptName string, zoneIds []string) ([]string, error) {
+	routes := []string{}
+	for _, zoneId := range zoneIds {
+		resp, err := client.Workers.Routes.List(
+			context.TODO(),
+			workers.RouteListParams{
+				ZoneID: cloudflare.F(zoneId),
+			},
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, route := range resp.Result {
+			if route.Script == scriptName {
+				routes = append(routes, route.Pattern)
+			}
+		}
+	}
+
+	return routes, nil
+}
+
+func getWorkersCustomDomains(client *cloudflare.Client, accountId string, scriptName string) ([]string, error) {
+	resp, err := client.Workers.Domains.List(
+		context.TODO(),
+		workers.DomainListParams{
+			AccountID: cloudflare.F(accountId),
+			Service:   cloudflare.F(scriptName),
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	domains := []string{}
+	for _, domain := range resp.Result {
+		domains = append(domains, domain.Hostname)
+	}
+
+	return domains, nil
+}
+
+// GetWorkersStatus reports on a workers script. Routes are zone scoped, so
+// they are only looked up in the zones listed in the resource's options
+func GetWorkersStatus(account Account, scriptName string, options *types.WorkersOptions) (WorkersStatus, error) {
+	scripts, err := account.Client.Workers.Scripts.List(
+		context.TODO(),
+		workers.ScriptListParams{
+			AccountID: cloudflare.F(account.AccountID),
+		},
+	)
+
+	if err != nil {
+		return WorkersStatus{}, err
+	}
+
+	scriptIdx := slices.IndexFunc(
+		scripts.Result,
+		func(s workers.Script) bool { return s.ID == scriptName },
+	)
+	if scriptIdx == -1 {
+		return WorkersStatus{
+			InstanceExists: false,
+		}, nil
+	}
+
+	script := scripts.Result[scriptIdx]
+
+	deploymentsResp, err := account.Client.Workers.Scripts.Deployments.Get(
+		context.TODO(),
+		scriptName,
+		workers.ScriptDeploymentGetParams{
+			AccountID: cloudflare.F(account.AccountID),
+		},
+	)
+
+	if err != nil {
+		return WorkersStatus{}, err
+	}
+
+	status := WorkersStatus{
+		InstanceExists: true,
+		ModifiedOn:     script.ModifiedOn,
+		Bindings:       []WorkersBinding{},
+	}
+
+	// created_on is an RFC 3339 timestamp, so the lexically greatest is the
+	// most recent deployment
+	var latest *workers.ScriptDeploymentGetResponseDeployment
+	for _, deployment := range deploymentsResp.Deployments {
+		if latest == nil || deployment.CreatedOn > latest.CreatedOn {
+			latest = &deployment
+		}
+	}
+
+	if latest != nil {
+		versions := []WorkersVersionTraffic{}
+		var primaryVersion WorkersVersionTraffic
+		for _, version := range latest.Versions {
+			traffic := WorkersVersionTraffic{
+				VersionID:  version.VersionID,
+				Percentage: version.Percentage,
+			}
+
+			if traffic.Percentage > primaryVersion.Percentage {
+				primaryVersion = traffic
+			}
+
+			versions = append(versions, traffic)
+		}
+
+		status.LatestDeployment = &WorkersDeployment{
+			ID:        latest.ID,
+			CreatedOn: latest.CreatedOn,
+			Source:    latest.Source,
+			Author:    latest.AuthorEmail,
+			Message:   latest.Annotations.WorkersMessage,
+			Versions:  versions,
+		}
+
+		// runtime settings and bindings come from the version serving
+		// the most traffic
+		if primaryVersion.VersionID != "" {
+			resources, err := getWorkersVersionResources(account.Client, account.AccountID, scriptName, primaryVersion.VersionID)
+			if err != nil {
+				return WorkersStatus{}, err
+			}
+
+			status.CompatibilityDate = resources.ScriptRuntime.CompatibilityDate
+			status.CompatibilityFlags = resources.ScriptRuntime.CompatibilityFlags
+			for _, binding := range resources.Bindings {
+				status.Bindings = append(status.Bindings, newWorkersBinding(binding))
+			}
+		}
+	}
+
+	zoneIds := []string{}
+	if options != nil {
+		zoneIds = options.Zones
+	}
+
+	status.Routes, err = getWorkersRoutes(account.Client, scriptName, zoneIds)
+	if err != nil {
+		return WorkersStatus{}, err
+	}
+
+	status.CustomDomains, err = getWorkersCustomDomains(account.Client, account.AccountID, scriptName)
+	if err != nil {
+		return WorkersStatus{}, err
+	}
+
+	return status, nil
+}
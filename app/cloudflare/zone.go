@@ -0,0 +1,138 @@
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"hermes/app/types"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cloudflare/cloudflare-go/v4"
+	"github.com/cloudflare/cloudflare-go/v4/zones"
+)
+
+var _ types.ResourceStatus = ZoneStatus{}
+
+type ZoneStatus struct {
+	InstanceExists      bool      `json:"exists"`
+	ZoneId              string    `json:"zone_id"`
+	Status              string    `json:"status"`
+	Paused              bool      `json:"paused"`
+	ActivatedOn         time.Time `json:"activated_on"`
+	NameServers         []string  `json:"name_servers"`
+	OriginalNameServers []string  `json:"original_name_servers"`
+	Mismatches          []string  `json:"mismatches"`
+}
+
+func (z ZoneStatus) IsResourceStatus() {}
+
+// GetHealth reports a paused zone, or one whose settings don't match those
+// expected, as unhealthy like a mismatched dns record
+func (z ZoneStatus) GetHealth() types.Health {
+	if !z.InstanceExists {
+		return types.Unhealthy("not found")
+	}
+
+	if z.Status != string(zones.ZoneStatusActive) {
+		return types.Unhealthy(fmt.Sprintf("zone is %s", z.Status))
+	}
+
+	if z.Paused {
+		return types.Unhealthy("zone is paused")
+	}
+
+	if len(z.Mismatches) > 0 {
+		return types.Unhealthy(strings.Join(z.Mismatches, ", "))
+	}
+
+	return types.Healthy()
+}
+
+func (z ZoneStatus) Exists() bool {
+	return z.InstanceExists
+}
+
+func (z ZoneStatus) GetStatusString() string {
+	if z.Paused {
+		return "paused"
+	}
+
+	if len(z.Mismatches) > 0 {
+		return "mismatch"
+	}
+
+	return z.Status
+}
+
+// findZone looks up a zone in the account by its domain name
+func findZone(client *cloudflare.Client, accountId string, zoneName string) (zones.Zone, bool, error) {
+	resp, err := client.Zones.List(
+		context.TODO(),
+		zones.ZoneListParams{
+			Account: cloudflare.F(zones.ZoneListParamsAccount{
+				ID: cloudflare.F(accountId),
+			}),
+			Name: cloudflare.F(zoneName),
+		},
+	)
+
+	if err != nil {
+		return zones.Zone{}, false, err
+	}
+
+	if len(resp.Result) == 0 {
+		return zones.Zone{}, false, nil
+	}
+
+	return resp.Result[0], true, nil
+}
+
+// normalizeHostnames lowercases hostnames and strips any trailing dot so
+// they can be compared regardless of how they were written
+func normalizeHostnames(hostnames []string) []string {
+	normalized := []string{}
+	for _, hostname := range hostnames {
+		normalized = append(normalized, strings.TrimSuffix(strings.ToLower(hostname), "."))
+	}
+
+	slices.Sort(normalized)
+
+	return normalized
+}
+
+func GetZoneStatus(account Account, zoneName string, options *types.ZoneOptions) (ZoneStatus, error) {
+	zone, found, err := findZone(account.Client, account.AccountID, zoneName)
+	if err != nil {
+		return ZoneStatus{}, err
+	}
+
+	if !found {
+		return ZoneStatus{
+			InstanceExists: false,
+		}, nil
+	}
+
+	mismatches := []string{}
+	if options != nil && len(options.NameServers) > 0 {
+		expected := normalizeHostnames(options.NameServers)
+		actual := normalizeHostnames(zone.NameServers)
+
+		if !slices.Equal(expected, actual) {
+			mismatches = append(mismatches,
+				fmt.Sprintf("name_servers: expected %v, found %v", expected, actual),
+			)
+		}
+	}
+
+	return ZoneStatus{
+		InstanceExists:      true,
+		ZoneId:              zone.ID,
+		Status:              string(zone.Status),
+		Paused:              zone.Paused,
+		ActivatedOn:         zone.ActivatedOn,
+		NameServers:         zone.NameServers,
+		OriginalNameServers: zone.OriginalNameServers,
+		Mismatches:          mismatches,
+	}, nil
+}
@@ -0,0 +1,45 @@
+package cloudflare
+
+import (
+	"hermes/app/types"
+	"testing"
+)
+
+func TestZoneStatusGetHealth(t *testing.T) {
+	tests := []struct {
+		name   string
+		status ZoneStatus
+		want   types.HealthState
+	}{
+		{"not found", ZoneStatus{}, types.HealthUnhealthy},
+		{"active", ZoneStatus{InstanceExists: true, Status: "active"}, types.HealthHealthy},
+		{"pending", ZoneStatus{InstanceExists: true, Status: "pending"}, types.HealthUnhealthy},
+		{"paused", ZoneStatus{InstanceExists: true, Status: "active", Paused: true}, types.HealthUnhealthy},
+		{
+			"name server mismatch",
+			ZoneStatus{
+				InstanceExists: true,
+				Status:         "active",
+				Mismatches:     []string{"name servers: expected [ada.ns.cloudflare.com], found [bob.ns.cloudflare.com]"},
+			},
+			types.HealthUnhealthy,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.status.GetHealth()
+			if got.State != test.want {
+				t.Errorf("got %s (%s), want %s", got.State, got.Reason, test.want)
+			}
+		})
+	}
+
+	// mismatches are judged the same for zones and dns records
+	mismatches := []string{"content: expected 192.0.2.1, found 192.0.2.2"}
+	zone := ZoneStatus{InstanceExists: true, Status: "active", Mismatches: mismatches}
+	record := DNSRecordStatus{InstanceExists: true, Mismatches: mismatches}
+	if zone.GetHealth() != record.GetHealth() {
+		t.Errorf("got zone %+v and dns record %+v, want the same health", zone.GetHealth(), record.GetHealth())
+	}
+}
@@ -0,0 +1,184 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"hermes/app/types"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// toJSONValue round trips a value through json, so values decoded from yaml
+// and values read from a status compare equal when they encode the same
+func toJSONValue(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded any
+	err = json.Unmarshal(data, &decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	return decoded, nil
+}
+
+// lookupPath resolves a dotted path such as services.api.desired_count
+// against a decoded status. A segment applied to a list picks the element
+// whose name field matches, or else the element at that index
+func lookupPath(value any, path string) (any, bool) {
+	for _, segment := range strings.Split(path, ".") {
+		switch current := value.(type) {
+		case map[string]any:
+			next, found := current[segment]
+			if !found {
+				return nil, false
+			}
+
+			value = next
+		case []any:
+			elementIdx := slices.IndexFunc(current, func(element any) bool {
+				object, ok := element.(map[string]any)
+				return ok && object["name"] == segment
+			})
+
+			if elementIdx == -1 {
+				index, err := strconv.Atoi(segment)
+				if err != nil || index < 0 || index >= len(current) {
+					return nil, false
+				}
+
+				elementIdx = index
+			}
+
+			value = current[elementIdx]
+		default:
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// typeHasPath reports whether the json a value of the given type encodes to
+// can hold the path. Any segment can name a list element or a map key, and
+// values that encode themselves are treated as single values
+func typeHasPath(valueType reflect.Type, segments []string) bool {
+	for valueType.Kind() == reflect.Pointer {
+		valueType = valueType.Elem()
+	}
+
+	if len(segments) == 0 {
+		return true
+	}
+
+	if valueType.Implements(jsonMarshalerType) || reflect.PointerTo(valueType).Implements(jsonMarshalerType) {
+		return false
+	}
+
+	switch valueType.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return typeHasPath(valueType.Elem(), segments[1:])
+	case reflect.Struct:
+		for i := range valueType.NumField() {
+			field := valueType.Field(i)
+
+			// untagged embedded structs have their fields promoted
+			if field.Anonymous && field.Tag.Get("json") == "" && field.Type.Kind() == reflect.Struct {
+				if typeHasPath(field.Type, segments) {
+					return true
+				}
+
+				continue
+			}
+
+			name, ok := jsonFieldName(field)
+			if ok && name == segments[0] {
+				return typeHasPath(field.Type, segments[1:])
+			}
+		}
+	}
+
+	return false
+}
+
+// ValidateExpect checks that each expected property is a path the status of
+// the given resource type can hold, so a typo is caught when the config is
+// loaded rather than reported as drift on every check
+func ValidateExpect(resourceType types.ResourceType, expect map[string]any) error {
+	if len(expect) == 0 {
+		return nil
+	}
+
+	status, found := statusTypes[resourceType]
+	if !found {
+		return fmt.Errorf("expected properties aren't supported for %s", resourceType)
+	}
+
+	paths := []string{}
+	for path := range expect {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		if !typeHasPath(reflect.TypeOf(status), strings.Split(path, ".")) {
+			return fmt.Errorf("unknown expected property for %s: %s", resourceType, path)
+		}
+
+		_, err := toJSONValue(expect[path])
+		if err != nil {
+			return fmt.Errorf("invalid expected value for %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// DetectDrift compares each expected property against the status, returning
+// the ones that differ. A property missing from the status, such as a list
+// element that isn't there, counts as drift, but a resource that doesn't
+// exist has no properties to compare
+func DetectDrift(expect map[string]any, status types.ResourceStatus) ([]types.Drift, error) {
+	drift := []types.Drift{}
+	if len(expect) == 0 || !status.Exists() {
+		return drift, nil
+	}
+
+	actualStatus, err := toJSONValue(status)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{}
+	for path := range expect {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		expected, err := toJSONValue(expect[path])
+		if err != nil {
+			return nil, fmt.Errorf("invalid expected value for %s: %w", path, err)
+		}
+
+		actual, found := lookupPath(actualStatus, path)
+		if !found || !reflect.DeepEqual(expected, actual) {
+			drift = append(drift, types.Drift{
+				Path:     path,
+				Expected: expected,
+				Actual:   actual,
+			})
+		}
+	}
+
+	return drift, nil
+}
@@ -0,0 +1,135 @@
+package common
+
+import (
+	"hermes/app/aws"
+	"hermes/app/types"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestLookupPath(t *testing.T) {
+	status := map[string]any{
+		"status": "ACTIVE",
+		"services": []any{
+			map[string]any{"name": "api", "desired_count": 2.0},
+			map[string]any{"name": "worker", "desired_count": 1.0},
+		},
+		"subnets": []any{"subnet-a", "subnet-b"},
+	}
+
+	tests := []struct {
+		path      string
+		want      any
+		wantFound bool
+	}{
+		{"status", "ACTIVE", true},
+		{"services.api.desired_count", 2.0, true},
+		{"services.worker", map[string]any{"name": "worker", "desired_count": 1.0}, true},
+		{"services.1.name", "worker", true},
+		{"subnets.0", "subnet-a", true},
+		{"services.web.desired_count", nil, false},
+		{"services.2", nil, false},
+		{"subnets.-1", nil, false},
+		{"status.code", nil, false},
+		{"missing", nil, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			got, found := lookupPath(status, test.path)
+			if found != test.wantFound || !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got (%v, %t), want (%v, %t)", got, found, test.want, test.wantFound)
+			}
+		})
+	}
+}
+
+func TestDetectDrift(t *testing.T) {
+	cluster := aws.ECSStatus{
+		InstanceExists: true,
+		Status:         "ACTIVE",
+		TasksRunning:   2,
+	}
+
+	tests := []struct {
+		name      string
+		expect    map[string]any
+		status    aws.ECSStatus
+		wantPaths []string
+	}{
+		{
+			name:   "int matches the decoded number",
+			expect: map[string]any{"tasks_running": 2, "status": "ACTIVE"},
+			status: cluster,
+		},
+		{
+			name:   "float matches the decoded number",
+			expect: map[string]any{"tasks_running": 2.0},
+			status: cluster,
+		},
+		{
+			name:      "different number",
+			expect:    map[string]any{"tasks_running": 3, "status": "ACTIVE"},
+			status:    cluster,
+			wantPaths: []string{"tasks_running"},
+		},
+		{
+			name:      "missing list element",
+			expect:    map[string]any{"services.api.desired_count": 2},
+			status:    cluster,
+			wantPaths: []string{"services.api.desired_count"},
+		},
+		{
+			name:   "missing resource",
+			expect: map[string]any{"tasks_running": 2},
+			status: aws.ECSStatus{InstanceExists: false},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			drift, err := DetectDrift(test.expect, test.status)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			paths := []string{}
+			for _, d := range drift {
+				paths = append(paths, d.Path)
+			}
+
+			if !slices.Equal(paths, test.wantPaths) {
+				t.Errorf("got drift %+v, want paths %v", drift, test.wantPaths)
+			}
+		})
+	}
+}
+
+func TestValidateExpect(t *testing.T) {
+	tests := []struct {
+		name         string
+		resourceType types.ResourceType
+		expect       map[string]any
+		wantErr      bool
+	}{
+		{"top level field", types.ECSResource, map[string]any{"tasks_running": 2}, false},
+		{"list element by name", types.ECSResource, map[string]any{"services.api.desired_count": 2}, false},
+		{"list element by index", types.ECSResource, map[string]any{"services.0.status": "ACTIVE"}, false},
+		{"unknown field", types.ECSResource, map[string]any{"task_count": 2}, true},
+		{"unknown nested field", types.ECSResource, map[string]any{"services.api.replicas": 2}, true},
+		{"path into a scalar", types.ECSResource, map[string]any{"status.code": 200}, true},
+		{"path into a timestamp", types.ECSResource, map[string]any{"services.api.created_at.year": 2024}, true},
+		{"unsupported resource type", types.ResourceType("aws-s3"), map[string]any{"status": "ok"}, true},
+		{"nothing expected", types.ResourceType("aws-s3"), nil, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateExpect(test.resourceType, test.expect)
+			if (err != nil) != test.wantErr {
+				t.Errorf("got error %v, want error %t", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+package common
+
+import (
+	"fmt"
+	"hermes/app/aws"
+	"hermes/app/azure"
+	"hermes/app/cloudflare"
+	"hermes/app/docker"
+	"hermes/app/fly"
+	"hermes/app/gcp"
+	"hermes/app/github"
+	"hermes/app/k8s"
+	"hermes/app/netlify"
+	"hermes/app/probe"
+	"hermes/app/types"
+	"hermes/app/vercel"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+)
+
+// statusTypes holds the status each resource type reports, so health rules
+// can be checked against its fields when the config is loaded
+var statusTypes = map[types.ResourceType]types.ResourceStatus{
+	types.ECSResource:                    aws.ECSStatus{},
+	types.RDSResource:                    aws.RDSStatus{},
+	types.ELBResource:                    aws.ELBStatus{},
+	types.APIGatewayResource:             aws.APIGatewayStatus{},
+	types.APIGatewayRestResource:         aws.APIGatewayRestStatus{},
+	types.LambdaResource:                 aws.LambdaStatus{},
+	types.CloudFormationResource:         aws.CloudFormationStatus{},
+	types.CloudflarePagesResource:        cloudflare.PagesStatus{},
+	types.CloudflareWorkersResource:      cloudflare.WorkersStatus{},
+	types.CloudflareZoneResource:         cloudflare.ZoneStatus{},
+	types.CloudflareDNSRecordResource:    cloudflare.DNSRecordStatus{},
+	types.CloudflareTunnelResource:       cloudflare.TunnelStatus{},
+	types.CloudflareLoadBalancerResource: cloudflare.LoadBalancerStatus{},
+	types.CloudflareR2Resource:           cloudflare.R2Status{},
+	types.CloudflareKVResource:           cloudflare.KVStatus{},
+	types.CloudflareD1Resource:           cloudflare.D1Status{},
+	types.HTTPResource:                   probe.HTTPStatus{},
+	types.TLSResource:                    probe.TLSStatus{},
+	types.TCPResource:                    probe.TCPStatus{},
+	types.DNSResource:                    probe.DNSStatus{},
+	types.PostgresResource:               probe.DatabaseStatus{},
+	types.MySQLResource:                  probe.DatabaseStatus{},
+	types.RedisResource:                  probe.DatabaseStatus{},
+	types.K8sDeploymentResource:          k8s.WorkloadStatus{},
+	types.K8sStatefulSetResource:         k8s.WorkloadStatus{},
+	types.K8sDaemonSetResource:           k8s.WorkloadStatus{},
+	types.K8sPodResource:                 k8s.PodStatus{},
+	types.DockerContainerResource:        docker.ContainerStatus{},
+	types.GitHubWorkflowResource:         github.WorkflowStatus{},
+	types.GitHubReleaseResource:          github.ReleaseStatus{},
+	types.VercelProjectResource:          vercel.ProjectStatus{},
+	types.NetlifySiteResource:            netlify.SiteStatus{},
+	types.FlyAppResource:                 fly.AppStatus{},
+	types.GCPCloudRunResource:            gcp.CloudRunStatus{},
+	types.GCPCloudSQLResource:            gcp.CloudSQLStatus{},
+	types.AzureWebAppResource:            azure.WebAppStatus{},
+	types.AzureSQLResource:               azure.SQLStatus{},
+}
+
+// healthRuleKey identifies a compiled rule. The same expression compiles
+// differently for each status type, as each declares its own fields
+type healthRuleKey struct {
+	statusType reflect.Type
+	expression string
+}
+
+var healthPrograms sync.Map
+
+// jsonFieldName returns the name a struct field is encoded as, and false
+// for fields json skips
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return "", false
+	}
+
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, true
+}
+
+// celTypeNames are identifiers cel reserves for its own types, which a
+// status field can't be declared under
+var celTypeNames = map[string]bool{
+	"bool": true, "bytes": true, "double": true, "dyn": true, "int": true,
+	"list": true, "map": true, "null_type": true, "string": true, "type": true, "uint": true,
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// celType returns the cel type a status field is declared as. Scalars keep
+// their own type, lists and objects hold their json values, and optional
+// (pointer) fields are dynamic so they can be compared with null
+func celType(fieldType reflect.Type) *cel.Type {
+	switch fieldType {
+	case timeType:
+		return cel.TimestampType
+	case durationType:
+		return cel.DurationType
+	}
+
+	switch fieldType.Kind() {
+	case reflect.String:
+		return cel.StringType
+	case reflect.Bool:
+		return cel.BoolType
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cel.IntType
+	case reflect.Float32, reflect.Float64:
+		return cel.DoubleType
+	case reflect.Slice, reflect.Array:
+		return cel.ListType(cel.DynType)
+	case reflect.Map, reflect.Struct:
+		return cel.MapType(cel.StringType, cel.DynType)
+	default:
+		return cel.DynType
+	}
+}
+
+// celValue converts a status field to a value of its declared cel type
+func celValue(field reflect.Value) (any, error) {
+	switch field.Type() {
+	case timeType, durationType:
+		return field.Interface(), nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		return field.String(), nil
+	case reflect.Bool:
+		return field.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return field.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(field.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return field.Float(), nil
+	}
+
+	decoded, err := toJSONValue(field.Interface())
+	if err != nil || decoded != nil {
+		return decoded, err
+	}
+
+	// nil lists and maps encode as null, but are declared as containers
+	switch field.Kind() {
+	case reflect.Slice:
+		return []any{}, nil
+	case reflect.Map:
+		return map[string]any{}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// statusFields converts the top level fields of a status for evaluation.
+// Fields json would omit as empty are kept with their zero value, so a rule
+// can refer to them whether or not they are set
+func statusFields(status types.ResourceStatus) (map[string]any, error) {
+	value := reflect.ValueOf(status)
+	if value.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("status is not a struct")
+	}
+
+	fields := map[string]any{}
+	for i := range value.NumField() {
+		name, ok := jsonFieldName(value.Type().Field(i))
+		if !ok {
+			continue
+		}
+
+		converted, err := celValue(value.Field(i))
+		if err != nil {
+			return nil, err
+		}
+
+		fields[name] = converted
+	}
+
+	return fields, nil
+}
+
+// compileHealthRule type checks a health rule expression against the fields
+// of a status type, each declared with its cel type, caching the program
+func compileHealthRule(statusType reflect.Type, expression string) (cel.Program, error) {
+	key := healthRuleKey{statusType, expression}
+	if program, found := healthPrograms.Load(key); found {
+		return program.(cel.Program), nil
+	}
+
+	options := []cel.EnvOption{cel.CrossTypeNumericComparisons(true)}
+	for i := range statusType.NumField() {
+		name, ok := jsonFieldName(statusType.Field(i))
+		if ok && !celTypeNames[name] {
+			options = append(options, cel.Variable(name, celType(statusType.Field(i).Type)))
+		}
+	}
+
+	env, err := cel.NewEnv(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+
+	if !ast.OutputType().IsAssignableType(cel.BoolType) {
+		return nil, fmt.Errorf("evaluates to %s, not a bool", ast.OutputType())
+	}
+
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, err
+	}
+
+	healthPrograms.Store(key, program)
+
+	return program, nil
+}
+
+// ValidateHealthRules checks that each of the rules compiles against the
+// status of the given resource type
+func ValidateHealthRules(resourceType types.ResourceType, rules *types.HealthRules) error {
+	if rules == nil {
+		return nil
+	}
+
+	status, found := statusTypes[resourceType]
+	if !found {
+		return fmt.Errorf("health rules aren't supported for %s", resourceType)
+	}
+
+	for _, expression := range []string{rules.Unhealthy, rules.Degraded} {
+		if expression == "" {
+			continue
+		}
+
+		_, err := compileHealthRule(reflect.TypeOf(status), expression)
+		if err != nil {
+			return fmt.Errorf("invalid health rule %q: %w", expression, err)
+		}
+	}
+
+	return nil
+}
+
+func evaluateHealthRule(expression string, status types.ResourceStatus, fields map[string]any) (bool, error) {
+	program, err := compileHealthRule(reflect.TypeOf(status), expression)
+	if err != nil {
+		return false, err
+	}
+
+	value, _, err := program.Eval(fields)
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate health rule %q: %w", expression, err)
+	}
+
+	result, ok := value.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("health rule %q evaluated to %v, not a bool", expression, value)
+	}
+
+	return result, nil
+}
+
+// EvaluateHealth judges a status against a resource's health rules. For a
+// resource that exists, a matching unhealthy rule makes it unhealthy, and a
+// matching degraded rule degrades it when it is otherwise healthy. Unless the
+// rules replace the built in check, the status's own verdict stands when no
+// rule matches. A resource that doesn't exist keeps its own unhealthy verdict
+func EvaluateHealth(rules *types.HealthRules, status types.ResourceStatus) (types.Health, error) {
+	health := status.GetHealth()
+	if rules == nil || (rules.Unhealthy == "" && rules.Degraded == "") || !status.Exists() {
+		return health, nil
+	}
+
+	fields, err := statusFields(status)
+	if err != nil {
+		return types.Health{}, err
+	}
+
+	if rules.Unhealthy != "" {
+		unhealthy, err := evaluateHealthRule(rules.Unhealthy, status, fields)
+		if err != nil {
+			return types.Health{}, err
+		}
+
+		if unhealthy {
+			return types.Unhealthy(fmt.Sprintf("matched unhealthy rule: %s", rules.Unhealthy)), nil
+		}
+	}
+
+	if rules.ReplaceBuiltin != nil && *rules.ReplaceBuiltin {
+		health = types.Healthy()
+	}
+
+	if health.State == types.HealthHealthy && rules.Degraded != "" {
+		degraded, err := evaluateHealthRule(rules.Degraded, status, fields)
+		if err != nil {
+			return types.Health{}, err
+		}
+
+		if degraded {
+			return types.Degraded(fmt.Sprintf("matched degraded rule: %s", rules.Degraded)), nil
+		}
+	}
+
+	return health, nil
+}
@@ -0,0 +1,182 @@
+package common
+
+import (
+	"hermes/app/aws"
+	"hermes/app/fly"
+	"hermes/app/types"
+	"testing"
+)
+
+func TestEvaluateHealth(t *testing.T) {
+	replaceBuiltin := true
+	activeCluster := aws.ECSStatus{
+		InstanceExists: true,
+		Status:         "ACTIVE",
+		TasksRunning:   1,
+	}
+
+	tests := []struct {
+		name   string
+		rules  *types.HealthRules
+		status types.ResourceStatus
+		want   types.HealthState
+	}{
+		{
+			name:   "no rules uses the built in check",
+			status: activeCluster,
+			want:   types.HealthHealthy,
+		},
+		{
+			name:   "unhealthy rule matches",
+			rules:  &types.HealthRules{Unhealthy: `tasks_running < 2`},
+			status: activeCluster,
+			want:   types.HealthUnhealthy,
+		},
+		{
+			name:   "built in check stands when no rule matches",
+			rules:  &types.HealthRules{Unhealthy: `tasks_running < 1`},
+			status: aws.ECSStatus{InstanceExists: true, Status: "INACTIVE", TasksRunning: 1},
+			want:   types.HealthUnhealthy,
+		},
+		{
+			name:   "built in degraded verdict stands when no rule matches",
+			rules:  &types.HealthRules{Unhealthy: `app_status != "deployed"`},
+			status: fly.AppStatus{InstanceExists: true, AppStatus: "deployed", ReleaseStatus: "failed"},
+			want:   types.HealthDegraded,
+		},
+		{
+			name:   "rules replace the built in check",
+			rules:  &types.HealthRules{Unhealthy: `tasks_running < 1`, ReplaceBuiltin: &replaceBuiltin},
+			status: aws.ECSStatus{InstanceExists: true, Status: "INACTIVE", TasksRunning: 1},
+			want:   types.HealthHealthy,
+		},
+		{
+			name:   "timestamp field",
+			rules:  &types.HealthRules{Degraded: `last_updated_time < timestamp("2024-01-01T00:00:00Z")`},
+			status: aws.CloudFormationStatus{InstanceExists: true, Status: "UPDATE_COMPLETE"},
+			want:   types.HealthDegraded,
+		},
+		{
+			name:   "degraded rule matches",
+			rules:  &types.HealthRules{Unhealthy: `status != "ACTIVE"`, Degraded: `tasks_running < 2`},
+			status: activeCluster,
+			want:   types.HealthDegraded,
+		},
+		{
+			name:   "nil list field is empty",
+			rules:  &types.HealthRules{Degraded: `size(services) == 0`},
+			status: activeCluster,
+			want:   types.HealthDegraded,
+		},
+		{
+			name:   "degraded rule doesn't apply to an unhealthy resource",
+			rules:  &types.HealthRules{Degraded: `tasks_running < 2`},
+			status: aws.ECSStatus{InstanceExists: true, Status: "INACTIVE", TasksRunning: 1},
+			want:   types.HealthUnhealthy,
+		},
+		{
+			name:   "missing resource stays unhealthy",
+			rules:  &types.HealthRules{Unhealthy: `status == "FAILED"`},
+			status: aws.ECSStatus{InstanceExists: false},
+			want:   types.HealthUnhealthy,
+		},
+		{
+			name:   "omitted field holds its zero value",
+			rules:  &types.HealthRules{Degraded: `state_reason != ""`},
+			status: aws.LambdaStatus{InstanceExists: true, State: "Active"},
+			want:   types.HealthHealthy,
+		},
+		{
+			name:   "set optional field",
+			rules:  &types.HealthRules{Degraded: `state_reason != ""`},
+			status: aws.LambdaStatus{InstanceExists: true, State: "Active", StateReason: "throttled"},
+			want:   types.HealthDegraded,
+		},
+		{
+			name:   "unset pointer field is null",
+			rules:  &types.HealthRules{Degraded: `free_storage_gib != null && free_storage_gib < 5.0`},
+			status: aws.RDSStatus{InstanceExists: true, Status: "available"},
+			want:   types.HealthHealthy,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			health, err := EvaluateHealth(test.rules, test.status)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if health.State != test.want {
+				t.Errorf("got %s (%s), want %s", health.State, health.Reason, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateHealthRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   types.HealthRules
+		wantErr bool
+	}{
+		{
+			name:  "known fields",
+			rules: types.HealthRules{Unhealthy: `status != "ACTIVE"`, Degraded: `tasks_running < 2`},
+		},
+		{
+			name:    "unknown field",
+			rules:   types.HealthRules{Unhealthy: `task_running < 2`},
+			wantErr: true,
+		},
+		{
+			name:    "syntax error",
+			rules:   types.HealthRules{Degraded: `tasks_running <`},
+			wantErr: true,
+		},
+		{
+			name:    "string field compared with a number",
+			rules:   types.HealthRules{Unhealthy: `status > 2`},
+			wantErr: true,
+		},
+		{
+			name:    "int field compared with a string",
+			rules:   types.HealthRules{Unhealthy: `tasks_running == "2"`},
+			wantErr: true,
+		},
+		{
+			name:  "int field compared with a double",
+			rules: types.HealthRules{Degraded: `tasks_running < 2.5`},
+		},
+		{
+			name:    "not a bool",
+			rules:   types.HealthRules{Degraded: `1 + 2`},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateHealthRules(types.ECSResource, &test.rules)
+			if (err != nil) != test.wantErr {
+				t.Errorf("got error %v, want error %t", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateHealthRulesEveryType(t *testing.T) {
+	for resourceType := range statusTypes {
+		t.Run(string(resourceType), func(t *testing.T) {
+			err := ValidateHealthRules(resourceType, &types.HealthRules{Unhealthy: `!exists`})
+			if err != nil {
+				t.Error(err)
+			}
+
+			_, err = statusFields(statusTypes[resourceType])
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
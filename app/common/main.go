@@ -1,41 +1,206 @@
 package common
 
 import (
+	"errors"
 	"fmt"
+	"sync"
 
 	"hermes/app/aws"
+	"hermes/app/azure"
 	"hermes/app/cloudflare"
+	"hermes/app/docker"
+	"hermes/app/fly"
+	"hermes/app/gcp"
+	"hermes/app/github"
+	"hermes/app/k8s"
+	"hermes/app/netlify"
+	"hermes/app/probe"
 	"hermes/app/types"
+	"hermes/app/vercel"
 
-	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
-	"github.com/aws/aws-sdk-go-v2/service/ecs"
-	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
-	"github.com/aws/aws-sdk-go-v2/service/rds"
-	cloudflare_sdk "github.com/cloudflare/cloudflare-go/v4"
+	"k8s.io/client-go/kubernetes"
 )
 
+var ErrHistoryUnsupported = errors.New("resource type does not support history")
+
 type Clients struct {
-	EcsClient        *ecs.Client
-	RdsClient        *rds.Client
-	ElbClient        *elasticloadbalancingv2.Client
-	ApiGatewayClient *apigatewayv2.Client
-	CloudflareClient *cloudflare_sdk.Client
+	AWS                *aws.ClientCache
+	CloudflareAccounts map[string]cloudflare.Account
+	Kubernetes         *k8s.ClientCache
+	Docker             *docker.ClientCache
+	GitHub             *github.Client
+	Vercel             *vercel.Client
+	Netlify            *netlify.Client
+	Fly                *fly.Client
+	Azure              *azure.Client
+	// nil unless a gcp resource is configured
+	GCP *gcp.Client
+}
+
+// getAWSClients returns the service clients for the region and credentials
+// an aws resource is configured with
+func (c *Clients) getAWSClients(resource types.ResourceDefinition) (aws.ServiceClients, error) {
+	target := types.AWSTarget{}
+	if resource.AWS != nil {
+		target = *resource.AWS
+	}
+
+	return c.AWS.Get(target)
+}
+
+// getKubernetesClient returns the clientset for the cluster a k8s resource
+// is configured with
+func (c *Clients) getKubernetesClient(resource types.ResourceDefinition) (kubernetes.Interface, error) {
+	options := types.KubernetesOptions{}
+	if resource.Kubernetes != nil {
+		options = *resource.Kubernetes
+	}
+
+	return c.Kubernetes.Get(options)
+}
+
+// getDockerClient returns the client for the engine a docker resource is
+// configured with
+func (c *Clients) getDockerClient(resource types.ResourceDefinition) (*docker.Client, error) {
+	host := ""
+	if resource.Docker != nil {
+		host = resource.Docker.Host
+	}
+
+	return c.Docker.Get(host)
+}
+
+// getCloudflareAccount returns the account a cloudflare resource belongs to
+func (c *Clients) getCloudflareAccount(resource types.ResourceDefinition) (cloudflare.Account, error) {
+	return c.GetCloudflareAccount(resource.CloudflareAccount)
+}
+
+// GetCloudflareAccount returns a configured account by name, or the default
+// account when name is empty
+func (c *Clients) GetCloudflareAccount(name string) (cloudflare.Account, error) {
+	if name == "" {
+		name = cloudflare.DefaultAccountName
+	}
+
+	account, found := c.CloudflareAccounts[name]
+	if !found {
+		return cloudflare.Account{}, fmt.Errorf("cloudflare account not configured: %s", name)
+	}
+
+	return account, nil
 }
 
 func GetResourceStatus(c *Clients, resource types.ResourceDefinition) (types.ResourceStatus, error) {
 	var status types.ResourceStatus
 	var err error
+
+	var awsClients aws.ServiceClients
+	if resource.Type.IsAWS() {
+		awsClients, err = c.getAWSClients(resource)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var cloudflareAccount cloudflare.Account
+	if resource.Type.IsCloudflare() {
+		cloudflareAccount, err = c.getCloudflareAccount(resource)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var kubernetesClient kubernetes.Interface
+	if resource.Type.IsKubernetes() {
+		kubernetesClient, err = c.getKubernetesClient(resource)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var dockerClient *docker.Client
+	if resource.Type.IsDocker() {
+		dockerClient, err = c.getDockerClient(resource)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	switch resource.Type {
 	case types.ECSResource:
-		status, err = aws.GetECSStatus(c.EcsClient, resource.Identifier)
+		status, err = aws.GetECSStatus(awsClients.ECS, resource.Identifier)
 	case types.RDSResource:
-		status, err = aws.GetRDSStatus(c.RdsClient, resource.Identifier)
+		status, err = aws.GetRDSStatus(awsClients.RDS, awsClients.CloudWatch, resource.Identifier, resource.RDS)
 	case types.ELBResource:
-		status, err = aws.GetELBStatus(c.ElbClient, resource.Identifier)
+		status, err = aws.GetELBStatus(awsClients.ELB, resource.Identifier)
 	case types.APIGatewayResource:
-		status, err = aws.GetAPIGatewayStatus(c.ApiGatewayClient, resource.Identifier)
+		status, err = aws.GetAPIGatewayStatus(awsClients.APIGateway, resource.Identifier)
+	case types.APIGatewayRestResource:
+		status, err = aws.GetAPIGatewayRestStatus(awsClients.APIGatewayRest, resource.Identifier)
+	case types.LambdaResource:
+		status, err = aws.GetLambdaStatus(awsClients.Lambda, resource.Identifier)
+	case types.CloudFormationResource:
+		status, err = aws.GetCloudFormationStatus(awsClients.CloudFormation, resource.Identifier)
 	case types.CloudflarePagesResource:
-		status, err = cloudflare.GetPagesStatus(c.CloudflareClient, resource.Identifier)
+		status, err = cloudflare.GetPagesStatus(cloudflareAccount, resource.Identifier)
+	case types.CloudflareWorkersResource:
+		status, err = cloudflare.GetWorkersStatus(cloudflareAccount, resource.Identifier, resource.Workers)
+	case types.CloudflareZoneResource:
+		status, err = cloudflare.GetZoneStatus(cloudflareAccount, resource.Identifier, resource.Zone)
+	case types.CloudflareDNSRecordResource:
+		status, err = cloudflare.GetDNSRecordStatus(cloudflareAccount, resource.Identifier, resource.DNSRecord)
+	case types.CloudflareTunnelResource:
+		status, err = cloudflare.GetTunnelStatus(cloudflareAccount, resource.Identifier)
+	case types.CloudflareLoadBalancerResource:
+		status, err = cloudflare.GetLoadBalancerStatus(cloudflareAccount, resource.Identifier, resource.LoadBalancer)
+	case types.CloudflareR2Resource:
+		status, err = cloudflare.GetR2Status(cloudflareAccount, resource.Identifier)
+	case types.CloudflareKVResource:
+		status, err = cloudflare.GetKVStatus(cloudflareAccount, resource.Identifier)
+	case types.CloudflareD1Resource:
+		status, err = cloudflare.GetD1Status(cloudflareAccount, resource.Identifier)
+	case types.HTTPResource:
+		status, err = probe.GetHTTPStatus(resource.Identifier, resource.HTTP)
+	case types.TLSResource:
+		status, err = probe.GetTLSStatus(resource.Identifier, resource.TLS)
+	case types.TCPResource:
+		status, err = probe.GetTCPStatus(resource.Identifier, resource.TCP)
+	case types.DNSResource:
+		status, err = probe.GetDNSStatus(resource.Identifier, resource.DNS)
+	case types.PostgresResource:
+		status, err = probe.GetPostgresStatus(resource.Identifier, resource.Database)
+	case types.MySQLResource:
+		status, err = probe.GetMySQLStatus(resource.Identifier, resource.Database)
+	case types.RedisResource:
+		status, err = probe.GetRedisStatus(resource.Identifier, resource.Database)
+	case types.K8sDeploymentResource:
+		status, err = k8s.GetDeploymentStatus(kubernetesClient, resource.Identifier)
+	case types.K8sStatefulSetResource:
+		status, err = k8s.GetStatefulSetStatus(kubernetesClient, resource.Identifier)
+	case types.K8sDaemonSetResource:
+		status, err = k8s.GetDaemonSetStatus(kubernetesClient, resource.Identifier)
+	case types.K8sPodResource:
+		status, err = k8s.GetPodStatus(kubernetesClient, resource.Identifier)
+	case types.DockerContainerResource:
+		status, err = docker.GetContainerStatus(dockerClient, resource.Identifier)
+	case types.GitHubWorkflowResource:
+		status, err = github.GetWorkflowStatus(c.GitHub, resource.Identifier, resource.Workflow)
+	case types.GitHubReleaseResource:
+		status, err = github.GetReleaseStatus(c.GitHub, resource.Identifier, resource.Release)
+	case types.VercelProjectResource:
+		status, err = vercel.GetProjectStatus(c.Vercel, resource.Identifier)
+	case types.NetlifySiteResource:
+		status, err = netlify.GetSiteStatus(c.Netlify, resource.Identifier)
+	case types.FlyAppResource:
+		status, err = fly.GetAppStatus(c.Fly, resource.Identifier)
+	case types.GCPCloudRunResource:
+		status, err = gcp.GetCloudRunStatus(c.GCP, resource.Identifier)
+	case types.GCPCloudSQLResource:
+		status, err = gcp.GetCloudSQLStatus(c.GCP, resource.Identifier)
+	case types.AzureWebAppResource:
+		status, err = azure.GetWebAppStatus(c.Azure, resource.Identifier)
+	case types.AzureSQLResource:
+		status, err = azure.GetSQLStatus(c.Azure, resource.Identifier)
 	default:
 		return nil, fmt.Errorf("invalid resource type encountered: %s", resource.Type)
 	}
@@ -46,3 +211,107 @@ func GetResourceStatus(c *Clients, resource types.ResourceDefinition) (types.Res
 
 	return status, nil
 }
+
+// GetResourceSnapshot fetches a resource's status and evaluates it against
+// the resource's definition. Drift that can't be evaluated is reported on
+// the snapshot rather than discarding the status
+func GetResourceSnapshot(c *Clients, resource types.ResourceDefinition) (types.ResourceSnapshot, error) {
+	status, err := GetResourceStatus(c, resource)
+	if err != nil {
+		return types.ResourceSnapshot{}, err
+	}
+
+	driftError := ""
+	drift, err := DetectDrift(resource.Expect, status)
+	if err != nil {
+		driftError = err.Error()
+		drift = []types.Drift{}
+	}
+
+	health, err := EvaluateHealth(resource.Health, status)
+	if err != nil {
+		return types.ResourceSnapshot{}, err
+	}
+
+	exists := status.Exists()
+
+	return types.ResourceSnapshot{
+		Definition: resource,
+		Status:     status,
+		Health:     health,
+		Exists:     &exists,
+		Drifted:    len(drift) > 0,
+		Drift:      drift,
+		DriftError: driftError,
+	}, nil
+}
+
+// UnknownResourceSnapshot is the snapshot of a resource whose status
+// couldn't be fetched
+func UnknownResourceSnapshot(resource types.ResourceDefinition, err error) types.ResourceSnapshot {
+	return types.ResourceSnapshot{
+		Definition: resource,
+		Health:     types.Unknown(err.Error()),
+	}
+}
+
+// GetDeploymentSnapshot fetches every resource in a deployment concurrently
+// and rolls their health up. Resources whose status can't be fetched are
+// included with an unknown health, giving the error as the reason
+func GetDeploymentSnapshot(c *Clients, deployment types.DeploymentDefinition) types.DeploymentSnapshot {
+	snapshots := make([]types.ResourceSnapshot, len(deployment.Resources))
+
+	var wg sync.WaitGroup
+	for i, resource := range deployment.Resources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			snapshot, err := GetResourceSnapshot(c, resource)
+			if err != nil {
+				snapshot = UnknownResourceSnapshot(resource, err)
+			}
+
+			snapshots[i] = snapshot
+		}()
+	}
+
+	wg.Wait()
+
+	healths := []types.Health{}
+	for _, snapshot := range snapshots {
+		healths = append(healths, snapshot.Health)
+	}
+
+	return types.DeploymentSnapshot{
+		Name:      deployment.Name,
+		Health:    types.RollupHealth(healths),
+		Resources: snapshots,
+	}
+}
+
+func GetResourceHistory(c *Clients, resource types.ResourceDefinition, limit int) (types.ResourceHistory, error) {
+	var history types.ResourceHistory
+	var err error
+
+	var cloudflareAccount cloudflare.Account
+	if resource.Type.IsCloudflare() {
+		cloudflareAccount, err = c.getCloudflareAccount(resource)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch resource.Type {
+	case types.CloudflarePagesResource:
+		history, err = cloudflare.GetPagesHistory(cloudflareAccount, resource.Identifier, limit)
+	default:
+		return nil, ErrHistoryUnsupported
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
@@ -0,0 +1,35 @@
+package common
+
+import (
+	"hermes/app/types"
+	"sync"
+)
+
+// ProjectStore holds the current project definitions. They change as
+// discovery rules are re-evaluated, so the server and collector read them
+// through the store rather than holding their own copy
+type ProjectStore struct {
+	mu       sync.RWMutex
+	projects []types.ProjectDefinition
+}
+
+func NewProjectStore(projects []types.ProjectDefinition) *ProjectStore {
+	return &ProjectStore{
+		projects: projects,
+	}
+}
+
+// Get returns the current projects. Callers must not modify them
+func (s *ProjectStore) Get() []types.ProjectDefinition {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.projects
+}
+
+func (s *ProjectStore) Set(projects []types.ProjectDefinition) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.projects = projects
+}
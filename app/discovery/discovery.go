@@ -0,0 +1,200 @@
+package discovery
+
+import (
+	"hermes/app/aws"
+	"hermes/app/cloudflare"
+	"hermes/app/common"
+	"hermes/app/types"
+	"log"
+	"slices"
+	"strings"
+	"time"
+)
+
+const defaultInterval = 5 * time.Minute
+
+// Discoverer evaluates discovery rules and merges the resources they find
+// into the statically declared projects, publishing the result to a store
+type Discoverer struct {
+	static   []types.ProjectDefinition
+	rules    []types.DiscoveryRule
+	interval time.Duration
+	clients  *common.Clients
+	store    *common.ProjectStore
+
+	// per type health rules, given to discovered resources as they are
+	// to declared ones
+	healthRules map[types.ResourceType]types.HealthRules
+
+	// the last successful result of each rule, kept when a later
+	// evaluation fails so a transient error doesn't drop resources
+	results [][]types.ResourceDefinition
+}
+
+func NewDiscoverer(config types.Config, clients *common.Clients, store *common.ProjectStore) *Discoverer {
+	discoverer := &Discoverer{
+		static:      config.Projects,
+		interval:    defaultInterval,
+		clients:     clients,
+		store:       store,
+		healthRules: config.HealthRules,
+	}
+
+	if config.Discovery != nil {
+		discoverer.rules = config.Discovery.Rules
+
+		if config.Discovery.Interval != 0 {
+			discoverer.interval = config.Discovery.Interval
+		}
+	}
+
+	discoverer.results = make([][]types.ResourceDefinition, len(discoverer.rules))
+
+	return discoverer
+}
+
+// IsDiscoverable reports whether a discovery rule can match resources of
+// the given type
+func IsDiscoverable(resourceType types.ResourceType) bool {
+	return aws.IsDiscoverable(resourceType) || cloudflare.IsDiscoverable(resourceType)
+}
+
+// Run re-evaluates the rules every interval, and never returns
+func (d *Discoverer) Run() {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		d.Evaluate()
+	}
+}
+
+// Evaluate runs every rule once and publishes the merged projects
+func (d *Discoverer) Evaluate() {
+	for i, rule := range d.rules {
+		resources, err := d.evaluateRule(rule)
+		if err != nil {
+			log.Println("error evaluating discovery rule", rule.Project, rule.Deployment, err)
+			continue
+		}
+
+		d.results[i] = resources
+	}
+
+	d.store.Set(merge(d.static, d.rules, d.results))
+}
+
+func (d *Discoverer) evaluateRule(rule types.DiscoveryRule) ([]types.ResourceDefinition, error) {
+	awsTypes := []types.ResourceType{}
+	cloudflareTypes := []types.ResourceType{}
+	for _, resourceType := range rule.Types {
+		if resourceType.IsAWS() {
+			awsTypes = append(awsTypes, resourceType)
+		} else if resourceType.IsCloudflare() {
+			cloudflareTypes = append(cloudflareTypes, resourceType)
+		}
+	}
+
+	resources := []types.ResourceDefinition{}
+
+	if len(awsTypes) > 0 {
+		target := types.AWSTarget{}
+		if rule.AWS != nil {
+			target = *rule.AWS
+		}
+
+		awsClients, err := d.clients.AWS.Get(target)
+		if err != nil {
+			return nil, err
+		}
+
+		discovered, err := aws.DiscoverTaggedResources(awsClients.Tagging, rule.Tags, awsTypes)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, resource := range discovered {
+			resource.AWS = rule.AWS
+			resources = append(resources, resource)
+		}
+	}
+
+	if len(cloudflareTypes) > 0 {
+		account, err := d.clients.GetCloudflareAccount(rule.CloudflareAccount)
+		if err != nil {
+			return nil, err
+		}
+
+		discovered, err := cloudflare.DiscoverResources(account, rule.Name, cloudflareTypes)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, resource := range discovered {
+			resource.CloudflareAccount = rule.CloudflareAccount
+			resources = append(resources, resource)
+		}
+	}
+
+	for i, resource := range resources {
+		rules, found := d.healthRules[resource.Type]
+		if found {
+			resources[i].Health = &rules
+		}
+	}
+
+	slices.SortFunc(resources, func(a, b types.ResourceDefinition) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return resources, nil
+}
+
+// merge adds each rule's resources to a copy of the static projects.
+// Statically declared resources win over discovered ones with the same type
+// and identifier
+func merge(
+	static []types.ProjectDefinition,
+	rules []types.DiscoveryRule,
+	results [][]types.ResourceDefinition,
+) []types.ProjectDefinition {
+	projects := []types.ProjectDefinition{}
+	for _, project := range static {
+		deployments := []types.DeploymentDefinition{}
+		for _, deployment := range project.Deployments {
+			deployment.Resources = slices.Clone(deployment.Resources)
+			deployments = append(deployments, deployment)
+		}
+
+		project.Deployments = deployments
+		projects = append(projects, project)
+	}
+
+	for i, rule := range rules {
+		if len(results[i]) == 0 {
+			continue
+		}
+
+		projectIdx := slices.IndexFunc(projects, func(p types.ProjectDefinition) bool { return p.Name == rule.Project })
+		if projectIdx == -1 {
+			projects = append(projects, types.ProjectDefinition{Name: rule.Project})
+			projectIdx = len(projects) - 1
+		}
+
+		project := &projects[projectIdx]
+
+		deploymentIdx := slices.IndexFunc(project.Deployments, func(d types.DeploymentDefinition) bool { return d.Name == rule.Deployment })
+		if deploymentIdx == -1 {
+			project.Deployments = append(project.Deployments, types.DeploymentDefinition{Name: rule.Deployment})
+			deploymentIdx = len(project.Deployments) - 1
+		}
+
+		deployment := &project.Deployments[deploymentIdx]
+
+		for _, resource := range results[i] {
+			deployment.AddResource(resource)
+		}
+	}
+
+	return projects
+}
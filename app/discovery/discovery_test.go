@@ -0,0 +1,91 @@
+package discovery
+
+import (
+	"hermes/app/types"
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	static := []types.ProjectDefinition{
+		{
+			Name: "shop",
+			Deployments: []types.DeploymentDefinition{
+				{
+					Name: "production",
+					Resources: []types.ResourceDefinition{
+						{Name: "api", Identifier: "api-cluster", Type: types.ECSResource},
+						{Name: "db", Identifier: "shop-db", Type: types.RDSResource},
+					},
+				},
+			},
+		},
+	}
+
+	rules := []types.DiscoveryRule{
+		{Project: "shop", Deployment: "production"},
+		{Project: "shop", Deployment: "staging"},
+		{Project: "blog", Deployment: "production"},
+		{Project: "empty", Deployment: "production"},
+	}
+
+	results := [][]types.ResourceDefinition{
+		{
+			// already declared, so the static definition wins
+			{Name: "api-cluster", Identifier: "api-cluster", Type: types.ECSResource},
+			// the name is taken by a different resource
+			{Name: "api", Identifier: "api", Type: types.LambdaResource},
+			{Name: "worker", Identifier: "worker", Type: types.LambdaResource},
+		},
+		{
+			{Name: "api-cluster", Identifier: "api-cluster-staging", Type: types.ECSResource},
+		},
+		{
+			{Name: "blog", Identifier: "blog", Type: types.LambdaResource},
+		},
+		{},
+	}
+
+	want := []types.ProjectDefinition{
+		{
+			Name: "shop",
+			Deployments: []types.DeploymentDefinition{
+				{
+					Name: "production",
+					Resources: []types.ResourceDefinition{
+						{Name: "api", Identifier: "api-cluster", Type: types.ECSResource},
+						{Name: "db", Identifier: "shop-db", Type: types.RDSResource},
+						{Name: "api-aws-lambda", Identifier: "api", Type: types.LambdaResource},
+						{Name: "worker", Identifier: "worker", Type: types.LambdaResource},
+					},
+				},
+				{
+					Name: "staging",
+					Resources: []types.ResourceDefinition{
+						{Name: "api-cluster", Identifier: "api-cluster-staging", Type: types.ECSResource},
+					},
+				},
+			},
+		},
+		{
+			Name: "blog",
+			Deployments: []types.DeploymentDefinition{
+				{
+					Name: "production",
+					Resources: []types.ResourceDefinition{
+						{Name: "blog", Identifier: "blog", Type: types.LambdaResource},
+					},
+				},
+			},
+		},
+	}
+
+	got := merge(static, rules, results)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if len(static[0].Deployments[0].Resources) != 2 {
+		t.Errorf("merge modified the static projects: %+v", static)
+	}
+}
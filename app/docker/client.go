@@ -0,0 +1,140 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hermes/app/httpapi"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+const defaultHost = "unix:///var/run/docker.sock"
+
+const requestTimeout = 10 * time.Second
+
+var errNotFound = errors.New("docker object not found")
+
+// Client is a minimal Docker Engine API client, talking to the daemon over
+// a unix socket or tcp
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+// NewClient builds a client for host, which takes the same form as
+// DOCKER_HOST (unix:///path/to/socket, tcp://host:port or an http(s) url)
+func NewClient(host string) (*Client, error) {
+	hostURL, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid docker host %s: %w", host, err)
+	}
+
+	switch hostURL.Scheme {
+	case "unix":
+		socketPath := hostURL.Path
+		transport := &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", socketPath)
+			},
+		}
+
+		return &Client{
+			httpClient: &http.Client{Transport: transport, Timeout: requestTimeout},
+			// the host is ignored when dialing the socket
+			baseURL: "http://docker",
+		}, nil
+	case "tcp", "http":
+		return &Client{
+			httpClient: &http.Client{Timeout: requestTimeout},
+			baseURL:    "http://" + hostURL.Host,
+		}, nil
+	case "https":
+		return &Client{
+			httpClient: &http.Client{Timeout: requestTimeout},
+			baseURL:    "https://" + hostURL.Host,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported docker host scheme: %s", hostURL.Scheme)
+	}
+}
+
+// get fetches an Engine API path, decoding the json response into out. A
+// 404 returns errNotFound
+func (c *Client) get(path string, out any) error {
+	resp, err := c.httpClient.Get(c.baseURL + path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return httpapi.ResponseError("docker", resp, errorMessage)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// errorMessage returns the message of an Engine API error body
+func errorMessage(body []byte) string {
+	var response struct {
+		Message string `json:"message"`
+	}
+
+	if json.Unmarshal(body, &response) != nil {
+		return ""
+	}
+
+	return response.Message
+}
+
+// ClientCache builds clients on first use and caches them per host
+type ClientCache struct {
+	mu      sync.Mutex
+	clients map[string]*Client
+}
+
+func NewClientCache() *ClientCache {
+	return &ClientCache{
+		clients: map[string]*Client{},
+	}
+}
+
+// Get returns the client for host, falling back to DOCKER_HOST and then the
+// default local socket when host is empty
+func (c *ClientCache) Get(host string) (*Client, error) {
+	if host == "" {
+		host = os.Getenv("DOCKER_HOST")
+	}
+
+	if host == "" {
+		host = defaultHost
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	client, found := c.clients[host]
+	if found {
+		return client, nil
+	}
+
+	client, err := NewClient(host)
+	if err != nil {
+		return nil, err
+	}
+
+	c.clients[host] = client
+
+	return client, nil
+}
@@ -0,0 +1,107 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"hermes/app/types"
+	"net/url"
+	"strings"
+)
+
+var _ types.ResourceStatus = ContainerStatus{}
+
+type ContainerStatus struct {
+	InstanceExists bool   `json:"exists"`
+	Name           string `json:"name"`
+	State          string `json:"state"`
+	ExitCode       int    `json:"exit_code"`
+	StartedAt      string `json:"started_at"`
+	// empty when the container has no health check
+	Health        string `json:"health,omitempty"`
+	FailingStreak int    `json:"failing_streak"`
+	RestartCount  int    `json:"restart_count"`
+	Image         string `json:"image"`
+	ImageID       string `json:"image_id"`
+}
+
+func (c ContainerStatus) IsResourceStatus() {}
+
+// GetHealth reports a running container whose first health checks haven't
+// yet passed as degraded
+func (c ContainerStatus) GetHealth() types.Health {
+	switch {
+	case !c.InstanceExists:
+		return types.Unhealthy("not found")
+	case c.State != "running":
+		return types.Unhealthy(fmt.Sprintf("container is %s with exit code %d", c.State, c.ExitCode))
+	case c.Health == "starting":
+		return types.Degraded("health check starting")
+	case c.Health == "unhealthy":
+		return types.Unhealthy(fmt.Sprintf("failed %d health checks in a row", c.FailingStreak))
+	default:
+		return types.Healthy()
+	}
+}
+
+func (c ContainerStatus) Exists() bool {
+	return c.InstanceExists
+}
+
+func (c ContainerStatus) GetStatusString() string {
+	if c.State == "running" && c.Health != "" {
+		return c.Health
+	}
+
+	return c.State
+}
+
+type containerInspect struct {
+	Name         string `json:"Name"`
+	Image        string `json:"Image"`
+	RestartCount int    `json:"RestartCount"`
+	State        struct {
+		Status    string `json:"Status"`
+		ExitCode  int    `json:"ExitCode"`
+		StartedAt string `json:"StartedAt"`
+		Health    *struct {
+			Status        string `json:"Status"`
+			FailingStreak int    `json:"FailingStreak"`
+		} `json:"Health"`
+	} `json:"State"`
+	Config struct {
+		Image string `json:"Image"`
+	} `json:"Config"`
+}
+
+// GetContainerStatus inspects a container by id or name
+func GetContainerStatus(client *Client, identifier string) (ContainerStatus, error) {
+	var container containerInspect
+	err := client.get("/containers/"+url.PathEscape(identifier)+"/json", &container)
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			return ContainerStatus{
+				InstanceExists: false,
+			}, nil
+		}
+
+		return ContainerStatus{}, err
+	}
+
+	status := ContainerStatus{
+		InstanceExists: true,
+		Name:           strings.TrimPrefix(container.Name, "/"),
+		State:          container.State.Status,
+		ExitCode:       container.State.ExitCode,
+		StartedAt:      container.State.StartedAt,
+		RestartCount:   container.RestartCount,
+		Image:          container.Config.Image,
+		ImageID:        container.Image,
+	}
+
+	if container.State.Health != nil {
+		status.Health = container.State.Health.Status
+		status.FailingStreak = container.State.Health.FailingStreak
+	}
+
+	return status, nil
+}
@@ -0,0 +1,181 @@
+package docker
+
+import (
+	"fmt"
+	"hermes/app/types"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newFakeEngine serves a minimal Engine API over a unix socket, returning a
+// client pointed at it
+func newFakeEngine(t *testing.T) *Client {
+	t.Helper()
+
+	socketPath := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/{id}/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.PathValue("id") {
+		case "web":
+			fmt.Fprint(w, `{
+				"Name": "/web",
+				"Image": "sha256:abc",
+				"RestartCount": 2,
+				"State": {"Status": "running", "ExitCode": 0, "StartedAt": "2024-01-01T00:00:00Z"},
+				"Config": {"Image": "nginx:1.27"}
+			}`)
+		case "api":
+			fmt.Fprint(w, `{
+				"Name": "/api",
+				"State": {"Status": "running", "Health": {"Status": "unhealthy", "FailingStreak": 3}},
+				"Config": {"Image": "api:latest"}
+			}`)
+		case "warming":
+			fmt.Fprint(w, `{
+				"Name": "/warming",
+				"State": {"Status": "running", "Health": {"Status": "starting", "FailingStreak": 0}}
+			}`)
+		case "job":
+			fmt.Fprint(w, `{"Name": "/job", "State": {"Status": "exited", "ExitCode": 137}}`)
+		case "broken":
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"message": "daemon exploded\n"}`)
+		case "proxied":
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusBadGateway)
+			fmt.Fprint(w, "<html>bad gateway</html>")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"message": "No such container: %s"}`, r.PathValue("id"))
+		}
+	})
+
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+
+	client, err := NewClient("unix://" + socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
+
+func TestGetContainerStatus(t *testing.T) {
+	client := newFakeEngine(t)
+
+	tests := []struct {
+		identifier string
+		want       ContainerStatus
+		wantString string
+		wantHealth types.HealthState
+	}{
+		{
+			identifier: "web",
+			want: ContainerStatus{
+				InstanceExists: true,
+				Name:           "web",
+				State:          "running",
+				StartedAt:      "2024-01-01T00:00:00Z",
+				RestartCount:   2,
+				Image:          "nginx:1.27",
+				ImageID:        "sha256:abc",
+			},
+			wantString: "running",
+			wantHealth: types.HealthHealthy,
+		},
+		{
+			identifier: "api",
+			want: ContainerStatus{
+				InstanceExists: true,
+				Name:           "api",
+				State:          "running",
+				Health:         "unhealthy",
+				FailingStreak:  3,
+				Image:          "api:latest",
+			},
+			wantString: "unhealthy",
+			wantHealth: types.HealthUnhealthy,
+		},
+		{
+			identifier: "warming",
+			want: ContainerStatus{
+				InstanceExists: true,
+				Name:           "warming",
+				State:          "running",
+				Health:         "starting",
+			},
+			wantString: "starting",
+			wantHealth: types.HealthDegraded,
+		},
+		{
+			identifier: "job",
+			want: ContainerStatus{
+				InstanceExists: true,
+				Name:           "job",
+				State:          "exited",
+				ExitCode:       137,
+			},
+			wantString: "exited",
+			wantHealth: types.HealthUnhealthy,
+		},
+		{
+			identifier: "missing",
+			want:       ContainerStatus{InstanceExists: false},
+			wantHealth: types.HealthUnhealthy,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.identifier, func(t *testing.T) {
+			status, err := GetContainerStatus(client, test.identifier)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if status != test.want {
+				t.Errorf("got %+v, want %+v", status, test.want)
+			}
+
+			if status.GetStatusString() != test.wantString {
+				t.Errorf("got status %s, want %s", status.GetStatusString(), test.wantString)
+			}
+
+			if health := status.GetHealth(); health.State != test.wantHealth {
+				t.Errorf("got health %s (%s), want %s", health.State, health.Reason, test.wantHealth)
+			}
+		})
+	}
+}
+
+func TestGetContainerStatusErrors(t *testing.T) {
+	client := newFakeEngine(t)
+
+	tests := []struct {
+		identifier string
+		want       string
+	}{
+		{"broken", "docker api returned 500: daemon exploded"},
+		{"proxied", "docker api returned 502: <html>bad gateway</html>"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.identifier, func(t *testing.T) {
+			_, err := GetContainerStatus(client, test.identifier)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got error %v, want %q", err, test.want)
+			}
+		})
+	}
+}
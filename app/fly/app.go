@@ -0,0 +1,126 @@
+package fly
+
+import (
+	"errors"
+	"fmt"
+	"hermes/app/types"
+	"strings"
+	"time"
+)
+
+var _ types.ResourceStatus = AppStatus{}
+
+type AppStatus struct {
+	InstanceExists bool   `json:"exists"`
+	AppStatus      string `json:"app_status"`
+	Url            string `json:"url"`
+	// the latest release, empty when the app has never been deployed
+	ReleaseVersion     int       `json:"release_version"`
+	ReleaseStatus      string    `json:"status"`
+	ReleaseDescription string    `json:"release_description"`
+	ReleasedAt         time.Time `json:"released_at"`
+}
+
+func (a AppStatus) IsResourceStatus() {}
+
+// GetHealth reports a deployed app whose current release failed as
+// degraded, since machines from earlier releases keep running
+func (a AppStatus) GetHealth() types.Health {
+	switch {
+	case !a.InstanceExists:
+		return types.Unhealthy("not found")
+	case a.AppStatus != "deployed":
+		return types.Unhealthy(fmt.Sprintf("app is %s", a.AppStatus))
+	case a.ReleaseStatus == "failed":
+		return types.Degraded(fmt.Sprintf("release v%d failed", a.ReleaseVersion))
+	default:
+		return types.Healthy()
+	}
+}
+
+func (a AppStatus) Exists() bool {
+	return a.InstanceExists
+}
+
+func (a AppStatus) GetStatusString() string {
+	if a.ReleaseStatus == "" {
+		return a.AppStatus
+	}
+
+	return a.ReleaseStatus
+}
+
+const appQuery = `query($name: String!) {
+	app(name: $name) {
+		status
+		hostname
+		currentRelease {
+			version
+			status
+			description
+			createdAt
+		}
+	}
+}`
+
+func isNotFound(errs []graphQLError) bool {
+	for _, err := range errs {
+		if err.Extensions.Code == "NOT_FOUND" || strings.Contains(err.Message, "Could not find") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetAppStatus reads the state and latest release of an app by name
+func GetAppStatus(client *Client, identifier string) (AppStatus, error) {
+	var data struct {
+		App *struct {
+			Status         string `json:"status"`
+			Hostname       string `json:"hostname"`
+			CurrentRelease *struct {
+				Version     int       `json:"version"`
+				Status      string    `json:"status"`
+				Description string    `json:"description"`
+				CreatedAt   time.Time `json:"createdAt"`
+			} `json:"currentRelease"`
+		} `json:"app"`
+	}
+
+	errs, err := client.query(appQuery, map[string]any{"name": identifier}, &data)
+	if err != nil {
+		return AppStatus{}, err
+	}
+
+	if data.App == nil {
+		if isNotFound(errs) {
+			return AppStatus{
+				InstanceExists: false,
+			}, nil
+		}
+
+		if len(errs) > 0 {
+			return AppStatus{}, errors.New(errs[0].Message)
+		}
+
+		return AppStatus{
+			InstanceExists: false,
+		}, nil
+	}
+
+	status := AppStatus{
+		InstanceExists: true,
+		AppStatus:      data.App.Status,
+		Url:            "https://" + data.App.Hostname,
+	}
+
+	if release := data.App.CurrentRelease; release != nil {
+		status.ReleaseVersion = release.Version
+		status.ReleaseStatus = strings.ToLower(release.Status)
+		status.ReleaseDescription = release.Description
+		status.ReleasedAt = release.CreatedAt
+	}
+
+	return status, nil
+}
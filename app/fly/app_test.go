@@ -0,0 +1,128 @@
+package fly
+
+import (
+	"encoding/json"
+	"fmt"
+	"hermes/app/types"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newStubFly answers the app query for a few app names, returning a client
+// pointed at it
+func newStubFly(t *testing.T) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/graphql" || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var request struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch request.Variables["name"] {
+		case "shop":
+			fmt.Fprint(w, `{"data": {"app": {
+				"status": "deployed",
+				"hostname": "shop.fly.dev",
+				"currentRelease": {"version": 42, "status": "FAILED", "description": "Deploy image", "createdAt": "2024-01-01T00:00:00Z"}
+			}}}`)
+		case "new":
+			fmt.Fprint(w, `{"data": {"app": {"status": "pending", "hostname": "new.fly.dev", "currentRelease": null}}}`)
+		case "forbidden":
+			fmt.Fprint(w, `{"data": {"app": null}, "errors": [{"message": "Not authorized to access this app", "extensions": {"code": "UNAUTHORIZED"}}]}`)
+		default:
+			fmt.Fprintf(w, `{"data": {"app": null}, "errors": [{"message": "Could not find App \"%s\"", "extensions": {"code": "NOT_FOUND"}}]}`, request.Variables["name"])
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("HERMES_TEST_FLY_TOKEN", "token")
+
+	return NewClient(&types.FlyDefinition{BaseURL: server.URL, TokenEnv: "HERMES_TEST_FLY_TOKEN"})
+}
+
+func TestGetAppStatus(t *testing.T) {
+	client := newStubFly(t)
+
+	tests := []struct {
+		name       string
+		want       AppStatus
+		wantHealth types.HealthState
+	}{
+		{
+			name: "shop",
+			want: AppStatus{
+				InstanceExists:     true,
+				AppStatus:          "deployed",
+				Url:                "https://shop.fly.dev",
+				ReleaseVersion:     42,
+				ReleaseStatus:      "failed",
+				ReleaseDescription: "Deploy image",
+				ReleasedAt:         time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			wantHealth: types.HealthDegraded,
+		},
+		{
+			name: "new",
+			want: AppStatus{
+				InstanceExists: true,
+				AppStatus:      "pending",
+				Url:            "https://new.fly.dev",
+			},
+			wantHealth: types.HealthUnhealthy,
+		},
+		{
+			name:       "missing",
+			want:       AppStatus{InstanceExists: false},
+			wantHealth: types.HealthUnhealthy,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, err := GetAppStatus(client, test.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(status, test.want) {
+				t.Errorf("got %+v, want %+v", status, test.want)
+			}
+
+			if health := status.GetHealth(); health.State != test.wantHealth {
+				t.Errorf("got health %s (%s), want %s", health.State, health.Reason, test.wantHealth)
+			}
+		})
+	}
+}
+
+func TestGetAppStatusErrors(t *testing.T) {
+	client := newStubFly(t)
+
+	_, err := GetAppStatus(client, "forbidden")
+	if err == nil || err.Error() != "Not authorized to access this app" {
+		t.Errorf("got error %v, want the graphql error", err)
+	}
+
+	client.token = "wrong"
+	_, err = GetAppStatus(client, "shop")
+	if err == nil || err.Error() != "fly api returned 401" {
+		t.Errorf("got error %v, want the status code", err)
+	}
+}
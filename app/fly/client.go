@@ -0,0 +1,107 @@
+package fly
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hermes/app/types"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultBaseURL = "https://api.fly.io"
+
+const defaultTokenEnv = "FLY_API_TOKEN"
+
+const requestTimeout = 10 * time.Second
+
+// Client is a minimal client for the Fly.io GraphQL api
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+// TokenEnvVar returns the environment variable the token is read from
+func TokenEnvVar(definition *types.FlyDefinition) string {
+	if definition != nil && definition.TokenEnv != "" {
+		return definition.TokenEnv
+	}
+
+	return defaultTokenEnv
+}
+
+// NewClient builds a client from the fly section of the config, which may
+// be nil
+func NewClient(definition *types.FlyDefinition) *Client {
+	client := &Client{
+		httpClient: &http.Client{Timeout: requestTimeout},
+		baseURL:    defaultBaseURL,
+		token:      os.Getenv(TokenEnvVar(definition)),
+	}
+
+	if definition != nil && definition.BaseURL != "" {
+		client.baseURL = strings.TrimSuffix(definition.BaseURL, "/")
+	}
+
+	return client
+}
+
+type graphQLError struct {
+	Message    string `json:"message"`
+	Extensions struct {
+		Code string `json:"code"`
+	} `json:"extensions"`
+}
+
+// query runs a graphql query, decoding the data field of the response into
+// out. Errors reported alongside the data are returned for the caller to
+// interpret
+func (c *Client) query(query string, variables map[string]any, out any) ([]graphQLError, error) {
+	body, err := json.Marshal(map[string]any{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+"/graphql", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fly api returned %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []graphQLError  `json:"errors"`
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.Data) > 0 {
+		err = json.Unmarshal(result.Data, out)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result.Errors, nil
+}
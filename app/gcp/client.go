@@ -0,0 +1,141 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hermes/app/httpapi"
+	"hermes/app/types"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	defaultRunEndpoint = "https://run.googleapis.com"
+	defaultSQLEndpoint = "https://sqladmin.googleapis.com"
+)
+
+const cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+const requestTimeout = 10 * time.Second
+
+var errNotFound = errors.New("gcp resource not found")
+
+// Client is a minimal client for the Cloud Run and Cloud SQL Admin REST
+// apis
+type Client struct {
+	httpClient  *http.Client
+	runEndpoint string
+	sqlEndpoint string
+	project     string
+	region      string
+}
+
+// NewClient builds a client from the gcp section of the config. Credentials
+// come from the configured service account json file, or else the
+// application default credentials. Without a credentials file, requests are
+// made unauthenticated when insecure_no_auth is set or both endpoints are
+// local, so a fake can be used
+func NewClient(definition *types.GCPDefinition) (*Client, error) {
+	if definition == nil {
+		definition = &types.GCPDefinition{}
+	}
+
+	client := &Client{
+		httpClient:  &http.Client{Timeout: requestTimeout},
+		runEndpoint: defaultRunEndpoint,
+		sqlEndpoint: defaultSQLEndpoint,
+		project:     definition.Project,
+		region:      definition.Region,
+	}
+
+	if definition.RunEndpoint != "" {
+		client.runEndpoint = strings.TrimSuffix(definition.RunEndpoint, "/")
+	}
+
+	if definition.SQLEndpoint != "" {
+		client.sqlEndpoint = strings.TrimSuffix(definition.SQLEndpoint, "/")
+	}
+
+	var credentials *google.Credentials
+	var err error
+	if definition.CredentialsFile != "" {
+		data, err := os.ReadFile(definition.CredentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gcp credentials file: %w", err)
+		}
+
+		credentials, err = google.CredentialsFromJSON(context.Background(), data, cloudPlatformScope)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gcp credentials file: %w", err)
+		}
+	} else if definition.InsecureNoAuth ||
+		(httpapi.IsLocalEndpoint(client.runEndpoint) && httpapi.IsLocalEndpoint(client.sqlEndpoint)) {
+		log.Println("gcp credentials skipped, requests to", client.runEndpoint, "and", client.sqlEndpoint, "are unauthenticated")
+	} else {
+		credentials, err = google.FindDefaultCredentials(context.Background(), cloudPlatformScope)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find gcp credentials: %w", err)
+		}
+	}
+
+	if credentials != nil {
+		client.httpClient = oauth2.NewClient(context.Background(), credentials.TokenSource)
+		client.httpClient.Timeout = requestTimeout
+
+		if client.project == "" {
+			client.project = credentials.ProjectID
+		}
+	}
+
+	return client, nil
+}
+
+// get fetches a full api url, decoding the response into out. Both the Cloud
+// Run and Cloud SQL apis answer a missing resource with a 404, returned as
+// errNotFound
+func (c *Client) get(url string, out any) error {
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return httpapi.ResponseError("gcp", resp, errorMessage)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// errorMessage returns the message of a google api error
+func errorMessage(body []byte) string {
+	var response struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	if json.Unmarshal(body, &response) != nil {
+		return ""
+	}
+
+	return response.Error.Message
+}
+
+// lastSegment returns the final component of a resource name such as
+// projects/p/locations/l/services/s/revisions/r
+func lastSegment(name string) string {
+	return name[strings.LastIndex(name, "/")+1:]
+}
@@ -0,0 +1,185 @@
+package gcp
+
+import (
+	"fmt"
+	"hermes/app/types"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newStubGCP starts separate fake Cloud Run and Cloud SQL Admin apis, so a
+// request sent to the wrong endpoint 404s, and returns a client pointed at
+// them
+func newStubGCP(t *testing.T) *Client {
+	t.Helper()
+
+	run := http.NewServeMux()
+	run.HandleFunc("/v2/projects/shop-prod/locations/europe-west1/services/{service}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.PathValue("service") {
+		case "api":
+			fmt.Fprint(w, `{
+				"uri": "https://api-abc.a.run.app",
+				"terminalCondition": {"state": "CONDITION_FAILED", "message": "Revision api-00002 is not ready"},
+				"latestReadyRevision": "projects/shop-prod/locations/europe-west1/services/api/revisions/api-00001",
+				"latestCreatedRevision": "projects/shop-prod/locations/europe-west1/services/api/revisions/api-00002",
+				"trafficStatuses": [
+					{"type": "TRAFFIC_TARGET_ALLOCATION_TYPE_LATEST", "percent": 90},
+					{"type": "TRAFFIC_TARGET_ALLOCATION_TYPE_REVISION", "revision": "api-00000", "percent": 10, "tag": "canary"}
+				]
+			}`)
+		case "flaky":
+			w.WriteHeader(http.StatusBadGateway)
+			fmt.Fprint(w, "<html>bad gateway</html>")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": {"code": 404, "message": "Resource not found"}}`)
+		}
+	})
+
+	sql := http.NewServeMux()
+	sql.HandleFunc("/v1/projects/{project}/instances/{instance}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("project") != "shop-prod" || r.PathValue("instance") != "db" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"error": {"code": 403, "message": "The client is not authorized to make this request."}}`)
+			return
+		}
+
+		fmt.Fprint(w, `{
+			"state": "RUNNABLE",
+			"databaseVersion": "POSTGRES_16",
+			"region": "europe-west1",
+			"gceZone": "europe-west1-b",
+			"secondaryGceZone": "europe-west1-c",
+			"settings": {"tier": "db-custom-2-7680", "availabilityType": "REGIONAL", "activationPolicy": "ALWAYS"}
+		}`)
+	})
+
+	runServer := httptest.NewServer(run)
+	t.Cleanup(runServer.Close)
+
+	sqlServer := httptest.NewServer(sql)
+	t.Cleanup(sqlServer.Close)
+
+	client, err := NewClient(&types.GCPDefinition{
+		Project:     "shop-prod",
+		Region:      "europe-west1",
+		RunEndpoint: runServer.URL + "/",
+		SQLEndpoint: sqlServer.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
+
+func TestGetCloudRunStatus(t *testing.T) {
+	client := newStubGCP(t)
+
+	want := CloudRunStatus{
+		InstanceExists:        true,
+		Uri:                   "https://api-abc.a.run.app",
+		ReadyState:            "CONDITION_FAILED",
+		ReadyMessage:          "Revision api-00002 is not ready",
+		LatestReadyRevision:   "api-00001",
+		LatestCreatedRevision: "api-00002",
+		Traffic: []TrafficTarget{
+			{Revision: "api-00001", Percent: 90, Latest: true},
+			{Revision: "api-00000", Percent: 10, Tag: "canary"},
+		},
+	}
+
+	for _, identifier := range []string{"api", "europe-west1/api", "shop-prod/europe-west1/api"} {
+		t.Run(identifier, func(t *testing.T) {
+			status, err := GetCloudRunStatus(client, identifier)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(status, want) {
+				t.Errorf("got %+v, want %+v", status, want)
+			}
+
+			if health := status.GetHealth(); health.State != types.HealthDegraded {
+				t.Errorf("got health %s (%s), want degraded", health.State, health.Reason)
+			}
+		})
+	}
+
+	status, err := GetCloudRunStatus(client, "missing")
+	if err != nil || status.InstanceExists {
+		t.Errorf("got (%+v, %v), want a missing service", status, err)
+	}
+
+	_, err = GetCloudRunStatus(client, "flaky")
+	if err == nil || err.Error() != "gcp api returned 502: <html>bad gateway</html>" {
+		t.Errorf("got error %v, want the raw body", err)
+	}
+}
+
+func TestGetCloudSQLStatus(t *testing.T) {
+	client := newStubGCP(t)
+
+	status, err := GetCloudSQLStatus(client, "db")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := CloudSQLStatus{
+		InstanceExists:   true,
+		State:            "RUNNABLE",
+		DatabaseVersion:  "POSTGRES_16",
+		Region:           "europe-west1",
+		Tier:             "db-custom-2-7680",
+		AvailabilityType: "REGIONAL",
+		ActivationPolicy: "ALWAYS",
+		Zone:             "europe-west1-b",
+		SecondaryZone:    "europe-west1-c",
+	}
+
+	if !reflect.DeepEqual(status, want) {
+		t.Errorf("got %+v, want %+v", status, want)
+	}
+
+	_, err = GetCloudSQLStatus(client, "other-project/db")
+	if err == nil || !strings.HasSuffix(err.Error(), "not authorized to make this request.") {
+		t.Errorf("got error %v, want the api message", err)
+	}
+}
+
+func TestNewClientCredentials(t *testing.T) {
+	// point the application default credentials at a file that doesn't
+	// exist, so any attempt to find them fails
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", filepath.Join(t.TempDir(), "missing.json"))
+
+	tests := []struct {
+		name       string
+		definition types.GCPDefinition
+		wantErr    bool
+	}{
+		{"default endpoints", types.GCPDefinition{}, true},
+		{"remote endpoint override", types.GCPDefinition{RunEndpoint: "https://run.example.com"}, true},
+		{"one local endpoint", types.GCPDefinition{RunEndpoint: "http://localhost:8080"}, true},
+		{
+			name:       "local endpoints",
+			definition: types.GCPDefinition{RunEndpoint: "http://localhost:8080", SQLEndpoint: "http://localhost:8081"},
+		},
+		{
+			name:       "insecure no auth",
+			definition: types.GCPDefinition{RunEndpoint: "https://run.example.com", InsecureNoAuth: true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewClient(&test.definition)
+			if (err != nil) != test.wantErr {
+				t.Errorf("got error %v, want error %t", err, test.wantErr)
+			}
+		})
+	}
+}
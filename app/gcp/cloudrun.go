@@ -0,0 +1,162 @@
+package gcp
+
+import (
+	"errors"
+	"fmt"
+	"hermes/app/types"
+	"net/url"
+	"strings"
+)
+
+var _ types.ResourceStatus = CloudRunStatus{}
+
+type CloudRunStatus struct {
+	InstanceExists        bool            `json:"exists"`
+	Uri                   string          `json:"uri"`
+	ReadyState            string          `json:"ready_state"`
+	ReadyMessage          string          `json:"ready_message,omitempty"`
+	LatestReadyRevision   string          `json:"latest_ready_revision"`
+	LatestCreatedRevision string          `json:"latest_created_revision"`
+	Traffic               []TrafficTarget `json:"traffic"`
+}
+
+type TrafficTarget struct {
+	Revision string `json:"revision"`
+	Percent  int    `json:"percent"`
+	Tag      string `json:"tag,omitempty"`
+	// set when traffic follows whichever revision is latest
+	Latest bool `json:"latest"`
+}
+
+func (c CloudRunStatus) IsResourceStatus() {}
+
+// GetHealth reports a service whose latest revision failed as degraded
+// while an earlier ready revision keeps serving
+func (c CloudRunStatus) GetHealth() types.Health {
+	switch {
+	case !c.InstanceExists:
+		return types.Unhealthy("not found")
+	case c.ReadyState == "CONDITION_SUCCEEDED":
+		return types.Healthy()
+	case c.LatestReadyRevision == "":
+		return types.Unhealthy(c.readyReason())
+	case c.ReadyState == "CONDITION_FAILED":
+		return types.Degraded(c.readyReason())
+	default:
+		return types.Healthy()
+	}
+}
+
+func (c CloudRunStatus) readyReason() string {
+	if c.ReadyMessage != "" {
+		return c.ReadyMessage
+	}
+
+	return fmt.Sprintf("service is %s", c.GetStatusString())
+}
+
+func (c CloudRunStatus) Exists() bool {
+	return c.InstanceExists
+}
+
+func (c CloudRunStatus) GetStatusString() string {
+	switch c.ReadyState {
+	case "CONDITION_SUCCEEDED":
+		return "ready"
+	case "CONDITION_FAILED":
+		return "failed"
+	default:
+		return strings.ToLower(strings.TrimPrefix(c.ReadyState, "CONDITION_"))
+	}
+}
+
+// parseCloudRunIdentifier accepts service, region/service or
+// project/region/service, filling the rest from the client's defaults
+func (c *Client) parseCloudRunIdentifier(identifier string) (string, string, string, error) {
+	project, region := c.project, c.region
+
+	parts := strings.Split(identifier, "/")
+	switch len(parts) {
+	case 1:
+	case 2:
+		region = parts[0]
+	case 3:
+		project, region = parts[0], parts[1]
+	default:
+		return "", "", "", fmt.Errorf("invalid cloud run identifier: %s", identifier)
+	}
+
+	if project == "" || region == "" {
+		return "", "", "", fmt.Errorf("cloud run identifier needs a project and region: %s", identifier)
+	}
+
+	return project, region, parts[len(parts)-1], nil
+}
+
+// GetCloudRunStatus reads a Cloud Run service's ready condition, latest
+// revisions and the traffic split actually being served
+func GetCloudRunStatus(client *Client, identifier string) (CloudRunStatus, error) {
+	project, region, name, err := client.parseCloudRunIdentifier(identifier)
+	if err != nil {
+		return CloudRunStatus{}, err
+	}
+
+	var service struct {
+		Uri               string `json:"uri"`
+		TerminalCondition struct {
+			State   string `json:"state"`
+			Message string `json:"message"`
+		} `json:"terminalCondition"`
+		LatestReadyRevision   string `json:"latestReadyRevision"`
+		LatestCreatedRevision string `json:"latestCreatedRevision"`
+		TrafficStatuses       []struct {
+			Type     string `json:"type"`
+			Revision string `json:"revision"`
+			Percent  int    `json:"percent"`
+			Tag      string `json:"tag"`
+		} `json:"trafficStatuses"`
+	}
+
+	err = client.get(
+		fmt.Sprintf("%s/v2/projects/%s/locations/%s/services/%s",
+			client.runEndpoint, url.PathEscape(project), url.PathEscape(region), url.PathEscape(name)),
+		&service,
+	)
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			return CloudRunStatus{
+				InstanceExists: false,
+			}, nil
+		}
+
+		return CloudRunStatus{}, err
+	}
+
+	traffic := []TrafficTarget{}
+	for _, target := range service.TrafficStatuses {
+		revision := target.Revision
+		// traffic following the latest revision doesn't name it
+		if revision == "" {
+			revision = service.LatestReadyRevision
+		}
+
+		traffic = append(traffic,
+			TrafficTarget{
+				Revision: lastSegment(revision),
+				Percent:  target.Percent,
+				Tag:      target.Tag,
+				Latest:   target.Type == "TRAFFIC_TARGET_ALLOCATION_TYPE_LATEST",
+			},
+		)
+	}
+
+	return CloudRunStatus{
+		InstanceExists:        true,
+		Uri:                   service.Uri,
+		ReadyState:            service.TerminalCondition.State,
+		ReadyMessage:          service.TerminalCondition.Message,
+		LatestReadyRevision:   lastSegment(service.LatestReadyRevision),
+		LatestCreatedRevision: lastSegment(service.LatestCreatedRevision),
+		Traffic:               traffic,
+	}, nil
+}
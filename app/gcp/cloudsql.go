@@ -0,0 +1,106 @@
+package gcp
+
+import (
+	"errors"
+	"fmt"
+	"hermes/app/types"
+	"net/url"
+	"strings"
+)
+
+var _ types.ResourceStatus = CloudSQLStatus{}
+
+type CloudSQLStatus struct {
+	InstanceExists  bool   `json:"exists"`
+	State           string `json:"state"`
+	DatabaseVersion string `json:"database_version"`
+	Region          string `json:"region"`
+	Tier            string `json:"tier"`
+	// ZONAL or REGIONAL (highly available)
+	AvailabilityType string `json:"availability_type"`
+	// an instance stopped by the user still reports RUNNABLE, with an
+	// activation policy of NEVER
+	ActivationPolicy string `json:"activation_policy"`
+	Zone             string `json:"zone"`
+	SecondaryZone    string `json:"secondary_zone,omitempty"`
+}
+
+func (c CloudSQLStatus) IsResourceStatus() {}
+
+func (c CloudSQLStatus) GetHealth() types.Health {
+	switch {
+	case !c.InstanceExists:
+		return types.Unhealthy("not found")
+	case c.State == "MAINTENANCE":
+		return types.Degraded("instance is in maintenance")
+	case c.State != "RUNNABLE", c.ActivationPolicy == "NEVER":
+		return types.Unhealthy(fmt.Sprintf("instance is %s", c.GetStatusString()))
+	default:
+		return types.Healthy()
+	}
+}
+
+func (c CloudSQLStatus) Exists() bool {
+	return c.InstanceExists
+}
+
+func (c CloudSQLStatus) GetStatusString() string {
+	if c.State == "RUNNABLE" && c.ActivationPolicy == "NEVER" {
+		return "stopped"
+	}
+
+	return strings.ToLower(c.State)
+}
+
+// GetCloudSQLStatus reads a Cloud SQL instance, identified by instance or
+// project/instance
+func GetCloudSQLStatus(client *Client, identifier string) (CloudSQLStatus, error) {
+	project, name, found := strings.Cut(identifier, "/")
+	if !found {
+		project, name = client.project, identifier
+	}
+
+	if project == "" {
+		return CloudSQLStatus{}, fmt.Errorf("cloud sql identifier needs a project: %s", identifier)
+	}
+
+	var instance struct {
+		State            string `json:"state"`
+		DatabaseVersion  string `json:"databaseVersion"`
+		Region           string `json:"region"`
+		GceZone          string `json:"gceZone"`
+		SecondaryGceZone string `json:"secondaryGceZone"`
+		Settings         struct {
+			Tier             string `json:"tier"`
+			AvailabilityType string `json:"availabilityType"`
+			ActivationPolicy string `json:"activationPolicy"`
+		} `json:"settings"`
+	}
+
+	err := client.get(
+		fmt.Sprintf("%s/v1/projects/%s/instances/%s",
+			client.sqlEndpoint, url.PathEscape(project), url.PathEscape(name)),
+		&instance,
+	)
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			return CloudSQLStatus{
+				InstanceExists: false,
+			}, nil
+		}
+
+		return CloudSQLStatus{}, err
+	}
+
+	return CloudSQLStatus{
+		InstanceExists:   true,
+		State:            instance.State,
+		DatabaseVersion:  instance.DatabaseVersion,
+		Region:           instance.Region,
+		Tier:             instance.Settings.Tier,
+		AvailabilityType: instance.Settings.AvailabilityType,
+		ActivationPolicy: instance.Settings.ActivationPolicy,
+		Zone:             instance.GceZone,
+		SecondaryZone:    instance.SecondaryGceZone,
+	}, nil
+}
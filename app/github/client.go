@@ -0,0 +1,118 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hermes/app/httpapi"
+	"hermes/app/types"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultBaseURL = "https://api.github.com"
+
+const requestTimeout = 10 * time.Second
+
+var errNotFound = errors.New("github resource not found")
+
+// Client is a minimal GitHub REST client. The base url can point at a
+// GitHub Enterprise server (https://host/api/v3) or a local stub
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+// NewClient builds a client from the github section of the config, which
+// may be nil. The token is read from the named environment variable, and
+// requests are made unauthenticated when no variable is configured
+func NewClient(definition *types.GitHubDefinition) (*Client, error) {
+	client := &Client{
+		httpClient: &http.Client{Timeout: requestTimeout},
+		baseURL:    defaultBaseURL,
+	}
+
+	if definition == nil {
+		return client, nil
+	}
+
+	if definition.BaseURL != "" {
+		client.baseURL = strings.TrimSuffix(definition.BaseURL, "/")
+	}
+
+	if definition.TokenEnv != "" {
+		token, found := os.LookupEnv(definition.TokenEnv)
+		if !found {
+			return nil, fmt.Errorf("github token environment variable %s not found", definition.TokenEnv)
+		}
+
+		client.token = token
+	}
+
+	return client, nil
+}
+
+// get makes a request against the versioned rest api, authenticated when a
+// token is set, and decodes the response into out. A 404 returns
+// errNotFound, which github also gives for private repositories the token
+// can't see
+func (c *Client) get(path string, out any) error {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return httpapi.ResponseError("github", resp, errorMessage)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// errorMessage returns the message github puts in an error body
+func errorMessage(body []byte) string {
+	var response struct {
+		Message string `json:"message"`
+	}
+
+	if json.Unmarshal(body, &response) != nil {
+		return ""
+	}
+
+	return response.Message
+}
+
+// splitRepository splits an owner/repo identifier, returning the remainder
+// of the identifier after the repository
+func splitRepository(identifier string) (string, string, string, error) {
+	parts := strings.SplitN(identifier, "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", fmt.Errorf("invalid github identifier, expected owner/repo: %s", identifier)
+	}
+
+	rest := ""
+	if len(parts) == 3 {
+		rest = parts[2]
+	}
+
+	return parts[0], parts[1], rest, nil
+}
@@ -0,0 +1,54 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"hermes/app/types"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientGetErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rate-limited":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message": "API rate limit exceeded"}`)
+		case "/proxied":
+			w.WriteHeader(http.StatusBadGateway)
+			fmt.Fprint(w, "<html>bad gateway</html>")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(&types.GitHubDefinition{BaseURL: server.URL + "/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/rate-limited", "github api returned 403: API rate limit exceeded"},
+		{"/proxied", "github api returned 502: <html>bad gateway</html>"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			var out any
+			err := client.get(test.path, &out)
+			if err == nil || err.Error() != test.want {
+				t.Errorf("got error %v, want %q", err, test.want)
+			}
+		})
+	}
+
+	var out any
+	if err := client.get("/missing", &out); !errors.Is(err, errNotFound) {
+		t.Errorf("got error %v, want not found", err)
+	}
+}
@@ -0,0 +1,93 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"hermes/app/types"
+	"time"
+)
+
+var _ types.ResourceStatus = ReleaseStatus{}
+
+type ReleaseStatus struct {
+	InstanceExists bool          `json:"exists"`
+	Tag            string        `json:"tag"`
+	Name           string        `json:"name"`
+	URL            string        `json:"url"`
+	PublishedAt    time.Time     `json:"published_at"`
+	Age            time.Duration `json:"age"`
+	// set when the release is older than the configured maximum age
+	Stale bool `json:"stale"`
+}
+
+func (r ReleaseStatus) IsResourceStatus() {}
+
+// GetHealth reports a stale release as degraded, since what was released
+// is still available
+func (r ReleaseStatus) GetHealth() types.Health {
+	if !r.InstanceExists {
+		return types.Unhealthy("no releases")
+	}
+
+	if r.Stale {
+		return types.Degraded(fmt.Sprintf("latest release is %d days old", int(r.Age.Hours()/24)))
+	}
+
+	return types.Healthy()
+}
+
+func (r ReleaseStatus) Exists() bool {
+	return r.InstanceExists
+}
+
+func (r ReleaseStatus) GetStatusString() string {
+	if !r.InstanceExists {
+		return "no-releases"
+	}
+
+	if r.Stale {
+		return "stale"
+	}
+
+	return "published"
+}
+
+// GetReleaseStatus reads the latest published release of an owner/repo.
+// Drafts and prereleases aren't considered
+func GetReleaseStatus(client *Client, identifier string, options *types.GitHubReleaseOptions) (ReleaseStatus, error) {
+	owner, repo, _, err := splitRepository(identifier)
+	if err != nil {
+		return ReleaseStatus{}, err
+	}
+
+	var release struct {
+		TagName     string    `json:"tag_name"`
+		Name        string    `json:"name"`
+		HTMLURL     string    `json:"html_url"`
+		PublishedAt time.Time `json:"published_at"`
+	}
+
+	err = client.get(fmt.Sprintf("/repos/%s/%s/releases/latest", owner, repo), &release)
+	if err != nil {
+		// github also returns a 404 for a repository with no releases
+		if errors.Is(err, errNotFound) {
+			return ReleaseStatus{
+				InstanceExists: false,
+			}, nil
+		}
+
+		return ReleaseStatus{}, err
+	}
+
+	age := time.Since(release.PublishedAt)
+
+	return ReleaseStatus{
+		InstanceExists: true,
+		Tag:            release.TagName,
+		Name:           release.Name,
+		URL:            release.HTMLURL,
+		PublishedAt:    release.PublishedAt,
+		Age:            age,
+		Stale:          options != nil && options.MaxAge != 0 && age > options.MaxAge,
+	}, nil
+}
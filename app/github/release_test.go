@@ -0,0 +1,31 @@
+package github
+
+import (
+	"hermes/app/types"
+	"testing"
+)
+
+func TestReleaseStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     ReleaseStatus
+		wantString string
+		wantHealth types.HealthState
+	}{
+		{"published", ReleaseStatus{InstanceExists: true, Tag: "v1.2.0"}, "published", types.HealthHealthy},
+		{"stale", ReleaseStatus{InstanceExists: true, Tag: "v1.0.0", Stale: true}, "stale", types.HealthDegraded},
+		{"no releases", ReleaseStatus{InstanceExists: false}, "no-releases", types.HealthUnhealthy},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.status.GetStatusString() != test.wantString {
+				t.Errorf("got status %s, want %s", test.status.GetStatusString(), test.wantString)
+			}
+
+			if health := test.status.GetHealth(); health.State != test.wantHealth {
+				t.Errorf("got health %s (%s), want %s", health.State, health.Reason, test.wantHealth)
+			}
+		})
+	}
+}
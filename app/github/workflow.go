@@ -0,0 +1,159 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"hermes/app/types"
+	"net/url"
+	"slices"
+	"time"
+)
+
+var _ types.ResourceStatus = WorkflowStatus{}
+
+type WorkflowStatus struct {
+	InstanceExists bool   `json:"exists"`
+	Branch         string `json:"branch"`
+	// the most recent run, which may still be in progress
+	LatestRun *WorkflowRun `json:"latest_run"`
+	// the most recent run that has finished, which health is based on
+	LastCompletedRun *WorkflowRun `json:"last_completed_run"`
+}
+
+type WorkflowRun struct {
+	ID         int64     `json:"id"`
+	RunNumber  int       `json:"run_number"`
+	Event      string    `json:"event"`
+	Status     string    `json:"status"`
+	Conclusion string    `json:"conclusion"`
+	HeadSHA    string    `json:"head_sha"`
+	URL        string    `json:"html_url"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// conclusions that don't indicate a broken pipeline
+var passingConclusions = []string{"success", "neutral", "skipped"}
+
+func (w WorkflowStatus) IsResourceStatus() {}
+
+func (w WorkflowStatus) GetHealth() types.Health {
+	if !w.InstanceExists {
+		return types.Unhealthy("not found")
+	}
+
+	if w.LastCompletedRun == nil {
+		return types.Unhealthy("no completed runs")
+	}
+
+	if !slices.Contains(passingConclusions, w.LastCompletedRun.Conclusion) {
+		return types.Unhealthy(fmt.Sprintf("run #%d concluded %s", w.LastCompletedRun.RunNumber, w.LastCompletedRun.Conclusion))
+	}
+
+	return types.Healthy()
+}
+
+func (w WorkflowStatus) Exists() bool {
+	return w.InstanceExists
+}
+
+func (w WorkflowStatus) GetStatusString() string {
+	if w.LastCompletedRun == nil {
+		return "no-runs"
+	}
+
+	return w.LastCompletedRun.Conclusion
+}
+
+type workflowRunsResponse struct {
+	WorkflowRuns []WorkflowRun `json:"workflow_runs"`
+}
+
+func getLatestRun(client *Client, path string, query url.Values) (*WorkflowRun, error) {
+	query.Set("per_page", "1")
+
+	var runs workflowRunsResponse
+	err := client.get(path+"?"+query.Encode(), &runs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(runs.WorkflowRuns) == 0 {
+		return nil, nil
+	}
+
+	return &runs.WorkflowRuns[0], nil
+}
+
+func getDefaultBranch(client *Client, owner string, repo string) (string, error) {
+	var repository struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+
+	err := client.get(fmt.Sprintf("/repos/%s/%s", owner, repo), &repository)
+	if err != nil {
+		return "", err
+	}
+
+	return repository.DefaultBranch, nil
+}
+
+// GetWorkflowStatus reads the latest runs of a workflow, identified by
+// owner/repo/workflow where workflow is the workflow file name or id. Runs
+// are taken from the configured branch, or the repository's default branch
+func GetWorkflowStatus(client *Client, identifier string, options *types.GitHubWorkflowOptions) (WorkflowStatus, error) {
+	owner, repo, workflow, err := splitRepository(identifier)
+	if err != nil {
+		return WorkflowStatus{}, err
+	}
+
+	if workflow == "" {
+		return WorkflowStatus{}, fmt.Errorf("invalid github workflow identifier, expected owner/repo/workflow: %s", identifier)
+	}
+
+	branch := ""
+	if options != nil {
+		branch = options.Branch
+	}
+
+	if branch == "" {
+		branch, err = getDefaultBranch(client, owner, repo)
+		if err != nil {
+			if errors.Is(err, errNotFound) {
+				return WorkflowStatus{
+					InstanceExists: false,
+				}, nil
+			}
+
+			return WorkflowStatus{}, err
+		}
+	}
+
+	path := fmt.Sprintf("/repos/%s/%s/actions/workflows/%s/runs", owner, repo, url.PathEscape(workflow))
+
+	latestRun, err := getLatestRun(client, path, url.Values{"branch": {branch}})
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			return WorkflowStatus{
+				InstanceExists: false,
+			}, nil
+		}
+
+		return WorkflowStatus{}, err
+	}
+
+	lastCompletedRun := latestRun
+	if latestRun != nil && latestRun.Status != "completed" {
+		lastCompletedRun, err = getLatestRun(client, path, url.Values{"branch": {branch}, "status": {"completed"}})
+		if err != nil {
+			return WorkflowStatus{}, err
+		}
+	}
+
+	return WorkflowStatus{
+		InstanceExists:   true,
+		Branch:           branch,
+		LatestRun:        latestRun,
+		LastCompletedRun: lastCompletedRun,
+	}, nil
+}
@@ -0,0 +1,50 @@
+// Package httpapi holds the pieces shared by the clients for json rest apis
+package httpapi
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ResponseError describes a failed response from the named api. message
+// pulls the error out of a body in the api's own format, returning "" when
+// the body isn't in that format, in which case the raw body is used so that
+// errors from proxies in front of the api aren't lost
+func ResponseError(api string, resp *http.Response, message func(body []byte) string) error {
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%s api returned %d: %w", api, resp.StatusCode, err)
+	}
+
+	text := strings.TrimSpace(message(raw))
+	if text == "" {
+		text = strings.TrimSpace(string(raw))
+	}
+
+	return fmt.Errorf("%s api returned %d: %s", api, resp.StatusCode, text)
+}
+
+// IsLocalEndpoint reports whether an api base url is plain http or on the
+// loopback interface, as a local fake of the api would be
+func IsLocalEndpoint(endpoint string) bool {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return false
+	}
+
+	if endpointURL.Scheme == "http" {
+		return true
+	}
+
+	host := endpointURL.Hostname()
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,66 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseError(t *testing.T) {
+	message := func(body []byte) string {
+		var response struct {
+			Message string `json:"message"`
+		}
+
+		json.Unmarshal(body, &response)
+		return response.Message
+	}
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"api error", `{"message": "not allowed\n"}`, "example api returned 403: not allowed"},
+		{"other json", `{"error": "not allowed"}`, `example api returned 403: {"error": "not allowed"}`},
+		{"html", "<html>forbidden</html>\n", "example api returned 403: <html>forbidden</html>"},
+		{"empty", "", "example api returned 403: "},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			recorder.WriteHeader(http.StatusForbidden)
+			recorder.WriteString(test.body)
+
+			err := ResponseError("example", recorder.Result(), message)
+			if err == nil || err.Error() != test.want {
+				t.Errorf("got error %v, want %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestIsLocalEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     bool
+	}{
+		{"http://fake:8080", true},
+		{"https://localhost:8443", true},
+		{"https://127.0.0.1:8443/", true},
+		{"https://[::1]:8443", true},
+		{"https://run.googleapis.com", false},
+		{"https://management.chinacloudapi.cn", false},
+		{"run.googleapis.com", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.endpoint, func(t *testing.T) {
+			if got := IsLocalEndpoint(test.endpoint); got != test.want {
+				t.Errorf("got %t, want %t", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package k8s
+
+import (
+	"hermes/app/types"
+	"strings"
+	"sync"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// ClientCache builds clientsets on first use and caches them per kubeconfig
+// and context
+type ClientCache struct {
+	mu      sync.Mutex
+	clients map[types.KubernetesOptions]kubernetes.Interface
+}
+
+func NewClientCache() *ClientCache {
+	return &ClientCache{
+		clients: map[types.KubernetesOptions]kubernetes.Interface{},
+	}
+}
+
+func (c *ClientCache) Get(options types.KubernetesOptions) (kubernetes.Interface, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	client, found := c.clients[options]
+	if found {
+		return client, nil
+	}
+
+	config, err := loadConfig(options)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err = kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	c.clients[options] = client
+
+	return client, nil
+}
+
+// loadConfig uses the in-cluster service account when hermes runs inside a
+// cluster and no kubeconfig was given, otherwise the usual kubeconfig
+// loading rules apply ($KUBECONFIG, then ~/.kube/config)
+func loadConfig(options types.KubernetesOptions) (*rest.Config, error) {
+	if options.Kubeconfig == "" && options.Context == "" {
+		config, err := rest.InClusterConfig()
+		if err == nil {
+			return config, nil
+		}
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if options.Kubeconfig != "" {
+		loadingRules.ExplicitPath = options.Kubeconfig
+	}
+
+	overrides := &clientcmd.ConfigOverrides{
+		CurrentContext: options.Context,
+	}
+
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+}
+
+// splitIdentifier splits a namespace/name identifier, defaulting to the
+// default namespace when none is given
+func splitIdentifier(identifier string) (string, string) {
+	namespace, name, found := strings.Cut(identifier, "/")
+	if !found {
+		return "default", identifier
+	}
+
+	return namespace, name
+}
@@ -0,0 +1,130 @@
+package k8s
+
+import (
+	"context"
+	"hermes/app/types"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var _ types.ResourceStatus = PodStatus{}
+
+type PodStatus struct {
+	InstanceExists bool              `json:"exists"`
+	Phase          string            `json:"phase"`
+	Ready          bool              `json:"ready"`
+	Node           string            `json:"node"`
+	Restarts       int               `json:"restarts"`
+	Containers     []ContainerStatus `json:"containers"`
+}
+
+func (p PodStatus) IsResourceStatus() {}
+
+func (p PodStatus) GetHealth() types.Health {
+	if !p.InstanceExists {
+		return types.Unhealthy("not found")
+	}
+
+	if p.Phase == string(corev1.PodSucceeded) ||
+		(p.Phase == string(corev1.PodRunning) && p.Ready) {
+		return types.Healthy()
+	}
+
+	if p.Phase == string(corev1.PodRunning) && p.GetStatusString() == p.Phase {
+		return types.Unhealthy("not ready")
+	}
+
+	return types.Unhealthy(p.GetStatusString())
+}
+
+func (p PodStatus) Exists() bool {
+	return p.InstanceExists
+}
+
+func (p PodStatus) GetStatusString() string {
+	// a waiting reason such as CrashLoopBackOff says more than the phase
+	for _, container := range p.Containers {
+		if container.Waiting != "" {
+			return container.Waiting
+		}
+	}
+
+	return p.Phase
+}
+
+type ContainerStatus struct {
+	Name     string `json:"name"`
+	Image    string `json:"image"`
+	Ready    bool   `json:"ready"`
+	Restarts int    `json:"restarts"`
+	// the reason a container is waiting, e.g. CrashLoopBackOff
+	Waiting string `json:"waiting,omitempty"`
+}
+
+func isPodReady(pod corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+func getContainerStatuses(pod corev1.Pod) []ContainerStatus {
+	containers := []ContainerStatus{}
+	for _, container := range pod.Status.ContainerStatuses {
+		waiting := ""
+		if container.State.Waiting != nil {
+			waiting = container.State.Waiting.Reason
+		}
+
+		containers = append(containers,
+			ContainerStatus{
+				Name:     container.Name,
+				Image:    container.Image,
+				Ready:    container.Ready,
+				Restarts: int(container.RestartCount),
+				Waiting:  waiting,
+			},
+		)
+	}
+
+	return containers
+}
+
+func countRestarts(pod corev1.Pod) int {
+	restarts := 0
+	for _, container := range pod.Status.ContainerStatuses {
+		restarts += int(container.RestartCount)
+	}
+
+	return restarts
+}
+
+func GetPodStatus(client kubernetes.Interface, identifier string) (PodStatus, error) {
+	namespace, name := splitIdentifier(identifier)
+
+	pod, err := client.CoreV1().Pods(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return PodStatus{
+				InstanceExists: false,
+			}, nil
+		}
+
+		return PodStatus{}, err
+	}
+
+	return PodStatus{
+		InstanceExists: true,
+		Phase:          string(pod.Status.Phase),
+		Ready:          isPodReady(*pod),
+		Node:           pod.Spec.NodeName,
+		Restarts:       countRestarts(*pod),
+		Containers:     getContainerStatuses(*pod),
+	}, nil
+}
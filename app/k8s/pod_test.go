@@ -0,0 +1,110 @@
+package k8s
+
+import (
+	"hermes/app/types"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetPodStatus(t *testing.T) {
+	readyCondition := func(status corev1.ConditionStatus) []corev1.PodCondition {
+		return []corev1.PodCondition{{Type: corev1.PodReady, Status: status}}
+	}
+
+	tests := []struct {
+		name         string
+		status       corev1.PodStatus
+		wantRestarts int
+		wantString   string
+		wantHealth   types.HealthState
+	}{
+		{
+			name: "running and ready",
+			status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: readyCondition(corev1.ConditionTrue),
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "app", RestartCount: 1, Ready: true},
+					{Name: "sidecar", RestartCount: 2, Ready: true},
+				},
+			},
+			wantRestarts: 3,
+			wantString:   "Running",
+			wantHealth:   types.HealthHealthy,
+		},
+		{
+			name: "crash looping",
+			status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: readyCondition(corev1.ConditionFalse),
+				ContainerStatuses: []corev1.ContainerStatus{
+					{
+						Name:         "app",
+						RestartCount: 7,
+						State: corev1.ContainerState{
+							Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+						},
+					},
+				},
+			},
+			wantRestarts: 7,
+			wantString:   "CrashLoopBackOff",
+			wantHealth:   types.HealthUnhealthy,
+		},
+		{
+			name: "running but not ready",
+			status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: readyCondition(corev1.ConditionFalse),
+			},
+			wantString: "Running",
+			wantHealth: types.HealthUnhealthy,
+		},
+		{
+			name:       "completed",
+			status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+			wantString: "Succeeded",
+			wantHealth: types.HealthHealthy,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "api-1", Namespace: "web"},
+				Status:     test.status,
+			}
+
+			status, err := GetPodStatus(fake.NewSimpleClientset(pod), "web/api-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if status.Restarts != test.wantRestarts {
+				t.Errorf("got %d restarts, want %d", status.Restarts, test.wantRestarts)
+			}
+
+			if status.GetStatusString() != test.wantString {
+				t.Errorf("got status %s, want %s", status.GetStatusString(), test.wantString)
+			}
+
+			if health := status.GetHealth(); health.State != test.wantHealth {
+				t.Errorf("got health %s (%s), want %s", health.State, health.Reason, test.wantHealth)
+			}
+		})
+	}
+}
+
+func TestGetPodStatusNotFound(t *testing.T) {
+	status, err := GetPodStatus(fake.NewSimpleClientset(), "web/api-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status.InstanceExists {
+		t.Error("expected the pod not to exist")
+	}
+}
@@ -0,0 +1,263 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"hermes/app/types"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var _ types.ResourceStatus = WorkloadStatus{}
+
+// WorkloadStatus is shared by deployments, statefulsets and daemonsets. For
+// daemonsets the replica counts are the number of nodes scheduled
+type WorkloadStatus struct {
+	InstanceExists    bool                `json:"exists"`
+	DesiredReplicas   int                 `json:"desired_replicas"`
+	ReadyReplicas     int                 `json:"ready_replicas"`
+	UpdatedReplicas   int                 `json:"updated_replicas"`
+	AvailableReplicas int                 `json:"available_replicas"`
+	Restarts          int                 `json:"restarts"`
+	Images            []string            `json:"images"`
+	Conditions        []WorkloadCondition `json:"conditions"`
+	// set when the controller hasn't yet observed the latest spec
+	RolloutPending bool `json:"rollout_pending"`
+	// set when a deployment has exceeded its progress deadline
+	RolloutFailed bool `json:"rollout_failed"`
+}
+
+func (w WorkloadStatus) IsResourceStatus() {}
+
+// GetHealth reports a workload with some but not all of its replicas ready
+// as degraded, e.g. part way through a rollout
+func (w WorkloadStatus) GetHealth() types.Health {
+	switch {
+	case !w.InstanceExists:
+		return types.Unhealthy("not found")
+	case w.RolloutFailed:
+		return types.Unhealthy("rollout exceeded its progress deadline")
+	case w.DesiredReplicas > 0 && w.ReadyReplicas == 0:
+		return types.Unhealthy("no ready replicas")
+	case w.ReadyReplicas < w.DesiredReplicas:
+		return types.Degraded(fmt.Sprintf("%d/%d replicas ready", w.ReadyReplicas, w.DesiredReplicas))
+	default:
+		return types.Healthy()
+	}
+}
+
+func (w WorkloadStatus) Exists() bool {
+	return w.InstanceExists
+}
+
+func (w WorkloadStatus) GetStatusString() string {
+	if !w.InstanceExists {
+		return "not-found"
+	}
+
+	if w.RolloutFailed {
+		return "rollout-failed"
+	}
+
+	if w.ReadyReplicas < w.DesiredReplicas {
+		return "not-ready"
+	}
+
+	if w.RolloutPending || w.UpdatedReplicas < w.DesiredReplicas {
+		return "progressing"
+	}
+
+	return "ready"
+}
+
+type WorkloadCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+func getImages(template corev1.PodTemplateSpec) []string {
+	images := []string{}
+	for _, container := range template.Spec.Containers {
+		images = append(images, container.Image)
+	}
+
+	return images
+}
+
+// countWorkloadRestarts sums container restarts across the pods matched by a
+// workload's selector
+func countWorkloadRestarts(client kubernetes.Interface, namespace string, selector *metav1.LabelSelector) (int, error) {
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return 0, err
+	}
+
+	pods, err := client.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labelSelector.String(),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	restarts := 0
+	for _, pod := range pods.Items {
+		restarts += countRestarts(pod)
+	}
+
+	return restarts, nil
+}
+
+func desiredReplicas(replicas *int32) int {
+	// the api server defaults an unset replica count to one
+	if replicas == nil {
+		return 1
+	}
+
+	return int(*replicas)
+}
+
+func GetDeploymentStatus(client kubernetes.Interface, identifier string) (WorkloadStatus, error) {
+	namespace, name := splitIdentifier(identifier)
+
+	deployment, err := client.AppsV1().Deployments(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return WorkloadStatus{
+				InstanceExists: false,
+			}, nil
+		}
+
+		return WorkloadStatus{}, err
+	}
+
+	restarts, err := countWorkloadRestarts(client, namespace, deployment.Spec.Selector)
+	if err != nil {
+		return WorkloadStatus{}, err
+	}
+
+	rolloutFailed := false
+	conditions := []WorkloadCondition{}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing &&
+			condition.Status == corev1.ConditionFalse &&
+			condition.Reason == "ProgressDeadlineExceeded" {
+			rolloutFailed = true
+		}
+
+		conditions = append(conditions,
+			WorkloadCondition{
+				Type:    string(condition.Type),
+				Status:  string(condition.Status),
+				Reason:  condition.Reason,
+				Message: condition.Message,
+			},
+		)
+	}
+
+	return WorkloadStatus{
+		InstanceExists:    true,
+		DesiredReplicas:   desiredReplicas(deployment.Spec.Replicas),
+		ReadyReplicas:     int(deployment.Status.ReadyReplicas),
+		UpdatedReplicas:   int(deployment.Status.UpdatedReplicas),
+		AvailableReplicas: int(deployment.Status.AvailableReplicas),
+		Restarts:          restarts,
+		Images:            getImages(deployment.Spec.Template),
+		Conditions:        conditions,
+		RolloutPending:    deployment.Status.ObservedGeneration < deployment.Generation,
+		RolloutFailed:     rolloutFailed,
+	}, nil
+}
+
+func GetStatefulSetStatus(client kubernetes.Interface, identifier string) (WorkloadStatus, error) {
+	namespace, name := splitIdentifier(identifier)
+
+	statefulSet, err := client.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return WorkloadStatus{
+				InstanceExists: false,
+			}, nil
+		}
+
+		return WorkloadStatus{}, err
+	}
+
+	restarts, err := countWorkloadRestarts(client, namespace, statefulSet.Spec.Selector)
+	if err != nil {
+		return WorkloadStatus{}, err
+	}
+
+	conditions := []WorkloadCondition{}
+	for _, condition := range statefulSet.Status.Conditions {
+		conditions = append(conditions,
+			WorkloadCondition{
+				Type:    string(condition.Type),
+				Status:  string(condition.Status),
+				Reason:  condition.Reason,
+				Message: condition.Message,
+			},
+		)
+	}
+
+	return WorkloadStatus{
+		InstanceExists:    true,
+		DesiredReplicas:   desiredReplicas(statefulSet.Spec.Replicas),
+		ReadyReplicas:     int(statefulSet.Status.ReadyReplicas),
+		UpdatedReplicas:   int(statefulSet.Status.UpdatedReplicas),
+		AvailableReplicas: int(statefulSet.Status.AvailableReplicas),
+		Restarts:          restarts,
+		Images:            getImages(statefulSet.Spec.Template),
+		Conditions:        conditions,
+		RolloutPending:    statefulSet.Status.ObservedGeneration < statefulSet.Generation,
+	}, nil
+}
+
+func GetDaemonSetStatus(client kubernetes.Interface, identifier string) (WorkloadStatus, error) {
+	namespace, name := splitIdentifier(identifier)
+
+	daemonSet, err := client.AppsV1().DaemonSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return WorkloadStatus{
+				InstanceExists: false,
+			}, nil
+		}
+
+		return WorkloadStatus{}, err
+	}
+
+	restarts, err := countWorkloadRestarts(client, namespace, daemonSet.Spec.Selector)
+	if err != nil {
+		return WorkloadStatus{}, err
+	}
+
+	conditions := []WorkloadCondition{}
+	for _, condition := range daemonSet.Status.Conditions {
+		conditions = append(conditions,
+			WorkloadCondition{
+				Type:    string(condition.Type),
+				Status:  string(condition.Status),
+				Reason:  condition.Reason,
+				Message: condition.Message,
+			},
+		)
+	}
+
+	return WorkloadStatus{
+		InstanceExists:    true,
+		DesiredReplicas:   int(daemonSet.Status.DesiredNumberScheduled),
+		ReadyReplicas:     int(daemonSet.Status.NumberReady),
+		UpdatedReplicas:   int(daemonSet.Status.UpdatedNumberScheduled),
+		AvailableReplicas: int(daemonSet.Status.NumberAvailable),
+		Restarts:          restarts,
+		Images:            getImages(daemonSet.Spec.Template),
+		Conditions:        conditions,
+		RolloutPending:    daemonSet.Status.ObservedGeneration < daemonSet.Generation,
+	}, nil
+}
@@ -0,0 +1,250 @@
+package k8s
+
+import (
+	"hermes/app/types"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newPod(name string, app string, restarts ...int32) *corev1.Pod {
+	containers := []corev1.ContainerStatus{}
+	for _, count := range restarts {
+		containers = append(containers, corev1.ContainerStatus{RestartCount: count})
+	}
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "web",
+			Labels:    map[string]string{"app": app},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: containers,
+		},
+	}
+}
+
+func newDeployment(replicas *int32, ready int32, conditions ...appsv1.DeploymentCondition) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "api",
+			Namespace:  "web",
+			Generation: 2,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": "api"},
+			},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "api", Image: "api:1.2.3"}},
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 2,
+			ReadyReplicas:      ready,
+			UpdatedReplicas:    ready,
+			AvailableReplicas:  ready,
+			Conditions:         conditions,
+		},
+	}
+}
+
+func replicaCount(count int32) *int32 {
+	return &count
+}
+
+func TestGetDeploymentStatus(t *testing.T) {
+	deadlineExceeded := appsv1.DeploymentCondition{
+		Type:   appsv1.DeploymentProgressing,
+		Status: corev1.ConditionFalse,
+		Reason: "ProgressDeadlineExceeded",
+	}
+
+	tests := []struct {
+		name         string
+		deployment   *appsv1.Deployment
+		wantDesired  int
+		wantRestarts int
+		wantFailed   bool
+		wantHealth   types.HealthState
+	}{
+		{
+			name:         "all replicas ready",
+			deployment:   newDeployment(replicaCount(3), 3),
+			wantDesired:  3,
+			wantRestarts: 3,
+			wantHealth:   types.HealthHealthy,
+		},
+		{
+			name:         "some replicas ready",
+			deployment:   newDeployment(replicaCount(3), 2),
+			wantDesired:  3,
+			wantRestarts: 3,
+			wantHealth:   types.HealthDegraded,
+		},
+		{
+			name:         "no replicas ready",
+			deployment:   newDeployment(replicaCount(3), 0),
+			wantDesired:  3,
+			wantRestarts: 3,
+			wantHealth:   types.HealthUnhealthy,
+		},
+		{
+			name:         "unset replicas default to one",
+			deployment:   newDeployment(nil, 1),
+			wantDesired:  1,
+			wantRestarts: 3,
+			wantHealth:   types.HealthHealthy,
+		},
+		{
+			name:         "scaled to zero",
+			deployment:   newDeployment(replicaCount(0), 0),
+			wantDesired:  0,
+			wantRestarts: 3,
+			wantHealth:   types.HealthHealthy,
+		},
+		{
+			name:         "progress deadline exceeded",
+			deployment:   newDeployment(replicaCount(3), 3, deadlineExceeded),
+			wantDesired:  3,
+			wantRestarts: 3,
+			wantFailed:   true,
+			wantHealth:   types.HealthUnhealthy,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(
+				test.deployment,
+				newPod("api-1", "api", 1),
+				newPod("api-2", "api", 0, 2),
+				newPod("worker-1", "worker", 5),
+			)
+
+			status, err := GetDeploymentStatus(client, "web/api")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !status.InstanceExists {
+				t.Fatal("deployment not found")
+			}
+
+			if status.DesiredReplicas != test.wantDesired {
+				t.Errorf("got %d desired replicas, want %d", status.DesiredReplicas, test.wantDesired)
+			}
+
+			if status.Restarts != test.wantRestarts {
+				t.Errorf("got %d restarts, want %d", status.Restarts, test.wantRestarts)
+			}
+
+			if status.RolloutFailed != test.wantFailed {
+				t.Errorf("got rollout failed %t, want %t", status.RolloutFailed, test.wantFailed)
+			}
+
+			if health := status.GetHealth(); health.State != test.wantHealth {
+				t.Errorf("got health %s (%s), want %s", health.State, health.Reason, test.wantHealth)
+			}
+		})
+	}
+}
+
+func TestGetDeploymentStatusRolloutPending(t *testing.T) {
+	deployment := newDeployment(replicaCount(2), 2)
+	deployment.Generation = 3
+
+	status, err := GetDeploymentStatus(fake.NewSimpleClientset(deployment), "web/api")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !status.RolloutPending {
+		t.Error("expected a pending rollout when the generation hasn't been observed")
+	}
+}
+
+func TestGetWorkloadStatusNotFound(t *testing.T) {
+	client := fake.NewSimpleClientset()
+
+	for name, get := range map[string]func() (WorkloadStatus, error){
+		"deployment":  func() (WorkloadStatus, error) { return GetDeploymentStatus(client, "web/api") },
+		"statefulset": func() (WorkloadStatus, error) { return GetStatefulSetStatus(client, "web/api") },
+		"daemonset":   func() (WorkloadStatus, error) { return GetDaemonSetStatus(client, "web/api") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			status, err := get()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if status.InstanceExists {
+				t.Error("expected the workload not to exist")
+			}
+
+			if status.GetStatusString() != "not-found" {
+				t.Errorf("got status %s, want not-found", status.GetStatusString())
+			}
+
+			if health := status.GetHealth(); health.State != types.HealthUnhealthy {
+				t.Errorf("got health %s, want unhealthy", health.State)
+			}
+		})
+	}
+}
+
+func TestGetStatefulSetStatus(t *testing.T) {
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "web"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: replicaCount(3),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+		},
+		Status: appsv1.StatefulSetStatus{ReadyReplicas: 1},
+	}
+
+	status, err := GetStatefulSetStatus(fake.NewSimpleClientset(statefulSet, newPod("db-0", "db", 4)), "web/db")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status.DesiredReplicas != 3 || status.ReadyReplicas != 1 || status.Restarts != 4 {
+		t.Errorf("got %d/%d ready with %d restarts, want 1/3 with 4", status.ReadyReplicas, status.DesiredReplicas, status.Restarts)
+	}
+
+	if health := status.GetHealth(); health.State != types.HealthDegraded {
+		t.Errorf("got health %s, want degraded", health.State)
+	}
+}
+
+func TestGetDaemonSetStatus(t *testing.T) {
+	daemonSet := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "default"},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "agent"}},
+		},
+		Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 4, NumberReady: 4},
+	}
+
+	objects := []runtime.Object{daemonSet}
+	status, err := GetDaemonSetStatus(fake.NewSimpleClientset(objects...), "agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status.DesiredReplicas != 4 || status.ReadyReplicas != 4 {
+		t.Errorf("got %d/%d nodes ready, want 4/4", status.ReadyReplicas, status.DesiredReplicas)
+	}
+
+	if health := status.GetHealth(); health.State != types.HealthHealthy {
+		t.Errorf("got health %s, want healthy", health.State)
+	}
+}
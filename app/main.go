@@ -2,18 +2,32 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"path"
+	"regexp"
 	"slices"
-	"strings"
+	"strconv"
 
 	"hermes/app/aws"
+	"hermes/app/azure"
 	"hermes/app/cloudflare"
 	"hermes/app/common"
+	"hermes/app/discovery"
+	"hermes/app/docker"
+	"hermes/app/fly"
+	"hermes/app/gcp"
+	"hermes/app/github"
+	"hermes/app/k8s"
+	"hermes/app/netlify"
+	"hermes/app/probe"
 	"hermes/app/prometheus"
+	"hermes/app/terraform"
 	"hermes/app/types"
+	"hermes/app/vercel"
 
 	prometheus_client "github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -72,7 +86,7 @@ type GetProjectsResponse struct {
 
 func (s *Server) GetProjectsHandler(w http.ResponseWriter, r *http.Request) {
 	err := json.NewEncoder(w).Encode(GetProjectsResponse{
-		Projects: s.Projects,
+		Projects: s.Projects.Get(),
 	})
 
 	if err != nil {
@@ -88,7 +102,7 @@ type GetProjectDefinitionResponse struct {
 func (s *Server) GetProjectDefinitionHandler(w http.ResponseWriter, r *http.Request) {
 	projectName := r.PathValue("project")
 
-	project, found := findProject(s.Projects, projectName)
+	project, found := findProject(s.Projects.Get(), projectName)
 	if !found {
 		http.Error(w, "project not found", http.StatusNotFound)
 		return
@@ -109,7 +123,7 @@ func (s *Server) GetResourceSnapshotHandler(w http.ResponseWriter, r *http.Reque
 	deploymentName := r.PathValue("deployment")
 	resourceName := r.PathValue("resource")
 
-	project, found := findProject(s.Projects, projectName)
+	project, found := findProject(s.Projects.Get(), projectName)
 	if !found {
 		http.Error(w, "project not found", http.StatusNotFound)
 		return
@@ -127,119 +141,102 @@ func (s *Server) GetResourceSnapshotHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	status, err := common.GetResourceStatus(&s.Clients, resource)
+	snapshot, err := common.GetResourceSnapshot(&s.Clients, resource)
 
 	if err != nil {
 		log.Println("error getting resource status", err)
-		http.Error(w, "failed to get resource status", http.StatusInternalServerError)
+		snapshot = common.UnknownResourceSnapshot(resource, err)
+	}
+
+	err = json.NewEncoder(w).Encode(snapshot)
+	if err != nil {
+		log.Println("failed to encode get resource snapshot response", err)
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
 		return
 	}
+}
 
-	snapshot := types.ResourceSnapshot{
-		Definition: resource,
-		Healthy:    status.IsHealthy(),
-		Exists:     status.Exists(),
-		Status:     status,
+const defaultHistoryLimit = 50
+
+func (s *Server) GetResourceHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	projectName := r.PathValue("project")
+	deploymentName := r.PathValue("deployment")
+	resourceName := r.PathValue("resource")
+
+	limit := defaultHistoryLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		parsedLimit, err := strconv.Atoi(limitParam)
+		if err != nil || parsedLimit <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+
+		limit = parsedLimit
+	}
+
+	project, found := findProject(s.Projects.Get(), projectName)
+	if !found {
+		http.Error(w, "project not found", http.StatusNotFound)
+		return
+	}
+
+	deployment, found := findDeployment(project, deploymentName)
+	if !found {
+		http.Error(w, "deployment not found", http.StatusNotFound)
+		return
+	}
+
+	resource, found := findResource(deployment, resourceName)
+	if !found {
+		http.Error(w, "resource not found", http.StatusNotFound)
+		return
+	}
+
+	history, err := common.GetResourceHistory(&s.Clients, resource, limit)
+
+	if errors.Is(err, common.ErrHistoryUnsupported) {
+		http.Error(w, "resource type does not support history", http.StatusBadRequest)
+		return
 	}
 
-	err = json.NewEncoder(w).Encode(snapshot)
 	if err != nil {
-		log.Println("failed to encode get resource snapshot response", err)
+		log.Println("error getting resource history", err)
+		http.Error(w, "failed to get resource history", http.StatusInternalServerError)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(history)
+	if err != nil {
+		log.Println("failed to encode get resource history response", err)
 		http.Error(w, "failed to encode response", http.StatusInternalServerError)
 		return
 	}
 }
 
-// type GetDeploymentSnapshotResponse struct {
-// 	Resources []types.ResourceSnapshot `json:"resources"`
-// }
-
-// func getResourceSnapshots(s *Server, resources []types.ResourceDefinition) []types.ResourceSnapshot {
-// 	ch := make(chan types.ResourceSnapshot, len(resources))
-// 	var wg sync.WaitGroup
-
-// 	for _, resource := range resources {
-// 		wg.Add(1)
-// 		fmt.Println("fetching")
-// 		switch resource.Type {
-// 		case types.ECSResource:
-// 			go func() {
-// 				defer wg.Done()
-
-// 				res, err := aws.GetECSStatus(s.ecsClient, resource.Identifier)
-
-// 				if err != nil {
-// 					fmt.Println("ecs fetch err", err)
-// 					return
-// 				}
-
-// 				snapshot := types.ResourceSnapshot{
-// 					Definition: resource,
-// 					Status:     res,
-// 				}
-
-// 				ch <- snapshot
-// 			}()
-// 		case types.RDSResource:
-// 			go func() {
-// 				defer wg.Done()
-
-// 				res, err := aws.GetRDSStatus(s.rdsClient, resource.Identifier)
-
-// 				if err != nil {
-// 					fmt.Println("rds fetch err", err)
-// 					return
-// 				}
-
-// 				snapshot := types.ResourceSnapshot{
-// 					Definition: resource,
-// 					Status:     res,
-// 				}
-
-// 				ch <- snapshot
-// 			}()
-// 		default:
-// 			fmt.Println("invalid resource type")
-// 		}
-// 	}
-
-// 	wg.Wait()
-// 	close(ch)
-
-// 	snapshots := []types.ResourceSnapshot{}
-// 	for resource := range ch {
-// 		snapshots = append(snapshots, resource)
-// 	}
-
-// 	return snapshots
-// }
-
-// func (s *Server) GetDeploymentSnapshotHandler(w http.ResponseWriter, r *http.Request) {
-// 	projectName := r.PathValue("project")
-// 	deploymentName := r.PathValue("deployment")
-
-// 	project, found := findProject(s.projects, projectName)
-// 	if !found {
-// 		http.Error(w, "project not found", http.StatusNotFound)
-// 	}
-
-// 	deployment, found := findDeployment(project, deploymentName)
-// 	if !found {
-// 		http.Error(w, "deployment not found", http.StatusNotFound)
-// 	}
-
-// 	resources := getResourceSnapshots(s, deployment.Resources)
-
-// 	resp := GetDeploymentSnapshotResponse{
-// 		Resources: resources,
-// 	}
-
-// 	err := json.NewEncoder(w).Encode(resp)
-// 	if err != nil {
-// 		log.Println("failed to encode get resource snapshot response", err)
-// 		http.Error(w, "failed to encode response", http.StatusInternalServerError)
-// 	}
-// }
+func (s *Server) GetDeploymentSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	projectName := r.PathValue("project")
+	deploymentName := r.PathValue("deployment")
+
+	project, found := findProject(s.Projects.Get(), projectName)
+	if !found {
+		http.Error(w, "project not found", http.StatusNotFound)
+		return
+	}
+
+	deployment, found := findDeployment(project, deploymentName)
+	if !found {
+		http.Error(w, "deployment not found", http.StatusNotFound)
+		return
+	}
+
+	snapshot := common.GetDeploymentSnapshot(&s.Clients, deployment)
+
+	err := json.NewEncoder(w).Encode(snapshot)
+	if err != nil {
+		log.Println("failed to encode get deployment snapshot response", err)
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
 
 func loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(
@@ -267,123 +264,558 @@ func corsMiddleware(next http.Handler) http.Handler {
 	)
 }
 
-func getProjectDefinitions() ([]types.ProjectDefinition, error) {
+func getConfig(awsClients *aws.ClientCache) (types.Config, error) {
 	data, err := os.ReadFile("projects.yaml")
 	if err != nil {
-		return []types.ProjectDefinition{}, err
+		return types.Config{}, err
+	}
+
+	var root yaml.Node
+	err = yaml.Unmarshal(data, &root)
+	if err != nil {
+		return types.Config{}, err
+	}
+
+	var config types.Config
+	if len(root.Content) > 0 && root.Content[0].Kind == yaml.SequenceNode {
+		// older configs are a bare list of projects
+		err = root.Decode(&config.Projects)
+	} else {
+		err = root.Decode(&config)
 	}
 
-	var projectDefinitions []types.ProjectDefinition
-	err = yaml.Unmarshal(data, &projectDefinitions)
 	if err != nil {
-		return []types.ProjectDefinition{}, err
+		return types.Config{}, err
+	}
+
+	// importing a stack calls aws, so its credentials are checked up front.
+	// Everything else is checked once targets have been resolved
+	err = checkEnvVars(getImportEnvVars(config))
+	if err != nil {
+		return types.Config{}, err
+	}
+
+	for i, project := range config.Projects {
+		err := importProject(&config.Projects[i], awsClients)
+		if err != nil {
+			return types.Config{}, fmt.Errorf("failed to import project %s: %w", project.Name, err)
+		}
+	}
+
+	cloudflareAccountNames := []string{}
+	for _, account := range config.CloudflareAccounts {
+		if slices.Contains(cloudflareAccountNames, account.Name) {
+			return types.Config{}, fmt.Errorf("duplicate cloudflare account: %s", account.Name)
+		}
+
+		cloudflareAccountNames = append(cloudflareAccountNames, account.Name)
 	}
 
 	// TODO: custom Unmarshaler
 	// https://stackoverflow.com/questions/53569573/parsing-string-to-enum-from-json-in-golang
-	for _, project := range projectDefinitions {
+	for _, project := range config.Projects {
 		for _, deployment := range project.Deployments {
 			for _, resource := range deployment.Resources {
 				if !types.IsResourceType(string(resource.Type)) {
-					return []types.ProjectDefinition{},
+					return types.Config{},
 						fmt.Errorf("invalid resource type: %s", resource.Name)
 				}
+
+				if resource.CloudflareAccount != "" &&
+					!slices.Contains(cloudflareAccountNames, resource.CloudflareAccount) {
+					return types.Config{},
+						fmt.Errorf("unknown cloudflare account %s: %s", resource.CloudflareAccount, resource.Name)
+				}
+
+				if resource.Type == types.CloudflareDNSRecordResource &&
+					(resource.DNSRecord == nil || resource.DNSRecord.Zone == "") {
+					return types.Config{},
+						fmt.Errorf("dns record resource has no zone: %s", resource.Name)
+				}
+
+				if resource.Type == types.CloudflareLoadBalancerResource &&
+					(resource.LoadBalancer == nil || resource.LoadBalancer.Zone == "") {
+					return types.Config{},
+						fmt.Errorf("load balancer resource has no zone: %s", resource.Name)
+				}
+
+				if resource.HTTP != nil && resource.HTTP.BodyRegex != "" {
+					_, err := regexp.Compile(resource.HTTP.BodyRegex)
+					if err != nil {
+						return types.Config{},
+							fmt.Errorf("invalid body regex for %s: %w", resource.Name, err)
+					}
+				}
+
+				if resource.TLS != nil && resource.TLS.MinVersion != "" &&
+					!probe.IsTLSVersion(resource.TLS.MinVersion) {
+					return types.Config{},
+						fmt.Errorf("invalid minimum tls version for %s: %s", resource.Name, resource.TLS.MinVersion)
+				}
+
+				if resource.Database != nil && resource.Database.PasswordEnv != "" &&
+					resource.Database.PasswordFile != "" {
+					return types.Config{},
+						fmt.Errorf("only one of password_env and password_file may be set for %s", resource.Name)
+				}
+
+				err := common.ValidateHealthRules(resource.Type, resource.Health)
+				if err != nil {
+					return types.Config{}, fmt.Errorf("%s: %w", resource.Name, err)
+				}
+
+				err = common.ValidateExpect(resource.Type, resource.Expect)
+				if err != nil {
+					return types.Config{}, fmt.Errorf("%s: %w", resource.Name, err)
+				}
 			}
 		}
 	}
 
-	return projectDefinitions, nil
+	for resourceType, rules := range config.HealthRules {
+		if !types.IsResourceType(string(resourceType)) {
+			return types.Config{}, fmt.Errorf("health rules for invalid resource type: %s", resourceType)
+		}
+
+		err := common.ValidateHealthRules(resourceType, &rules)
+		if err != nil {
+			return types.Config{}, fmt.Errorf("%s: %w", resourceType, err)
+		}
+	}
+
+	if config.Discovery != nil {
+		if config.Discovery.Interval < 0 {
+			return types.Config{}, fmt.Errorf("invalid discovery interval: %s", config.Discovery.Interval)
+		}
+
+		for _, rule := range config.Discovery.Rules {
+			err := validateDiscoveryRule(rule, cloudflareAccountNames)
+			if err != nil {
+				return types.Config{}, err
+			}
+		}
+	}
+
+	resolveAWSTargets(config.Projects)
+	resolveDiscoveryTargets(config)
+	resolveHealthRules(config)
+
+	return config, nil
 }
 
-func getRequiredEnvVars(projectDefinitions []types.ProjectDefinition) []string {
-	requiredCredentials := []string{}
+func validateDiscoveryRule(rule types.DiscoveryRule, cloudflareAccountNames []string) error {
+	if rule.Project == "" || rule.Deployment == "" {
+		return fmt.Errorf("discovery rule needs a project and deployment")
+	}
+
+	for _, resourceType := range rule.Types {
+		if !discovery.IsDiscoverable(resourceType) {
+			return fmt.Errorf("resource type can't be discovered: %s", resourceType)
+		}
+
+		if resourceType.IsAWS() && len(rule.Tags) == 0 {
+			return fmt.Errorf("discovery rule for %s/%s needs tags to match aws resources", rule.Project, rule.Deployment)
+		}
+
+		if resourceType.IsCloudflare() && rule.Name == "" {
+			return fmt.Errorf("discovery rule for %s/%s needs a name to match cloudflare resources", rule.Project, rule.Deployment)
+		}
+	}
+
+	if _, err := path.Match(rule.Name, ""); err != nil {
+		return fmt.Errorf("invalid name pattern for discovery rule %s/%s: %w", rule.Project, rule.Deployment, err)
+	}
+
+	if rule.CloudflareAccount != "" && !slices.Contains(cloudflareAccountNames, rule.CloudflareAccount) {
+		return fmt.Errorf("unknown cloudflare account %s in discovery rule %s/%s",
+			rule.CloudflareAccount, rule.Project, rule.Deployment)
+	}
+
+	return nil
+}
+
+// mergeDeployments adds imported deployments to a project. Declared
+// resources win over imported ones with the same type and identifier
+func mergeDeployments(project *types.ProjectDefinition, imported []types.DeploymentDefinition) {
+	for _, importedDeployment := range imported {
+		deploymentIdx := slices.IndexFunc(
+			project.Deployments,
+			func(d types.DeploymentDefinition) bool { return d.Name == importedDeployment.Name },
+		)
+		if deploymentIdx == -1 {
+			project.Deployments = append(project.Deployments, importedDeployment)
+			continue
+		}
+
+		for _, resource := range importedDeployment.Resources {
+			project.Deployments[deploymentIdx].AddResource(resource)
+		}
+	}
+}
+
+// importProject adds resources from a project's source to its declared
+// deployments
+func importProject(project *types.ProjectDefinition, awsClients *aws.ClientCache) error {
+	switch project.Source {
+	case "":
+		return nil
+	case "terraform":
+		if project.Terraform == nil || len(project.Terraform.States) == 0 {
+			return fmt.Errorf("terraform source has no states")
+		}
+
+		groupBy := project.Terraform.GroupBy
+		if groupBy != "" && groupBy != terraform.GroupByWorkspace && groupBy != terraform.GroupByModule {
+			return fmt.Errorf("invalid terraform group_by: %s", groupBy)
+		}
+
+		imported, err := terraform.Import(project.Terraform)
+		if err != nil {
+			return err
+		}
+
+		mergeDeployments(project, imported)
 
-	for _, project := range projectDefinitions {
+		return nil
+	case "cloudformation-stack":
+		if project.CloudFormation == nil || len(project.CloudFormation.Stacks) == 0 {
+			return fmt.Errorf("cloudformation source has no stacks")
+		}
+
+		target := types.AWSTarget{}
+		if project.AWS != nil {
+			target = *project.AWS
+		}
+
+		clients, err := awsClients.Get(target)
+		if err != nil {
+			return err
+		}
+
+		imported := []types.DeploymentDefinition{}
+		for _, stack := range project.CloudFormation.Stacks {
+			resources, err := aws.ImportStack(clients.CloudFormation, stack)
+			if err != nil {
+				return fmt.Errorf("failed to import stack %s: %w", stack, err)
+			}
+
+			imported = append(imported, types.DeploymentDefinition{
+				Name:      stack,
+				Resources: resources,
+			})
+		}
+
+		mergeDeployments(project, imported)
+
+		return nil
+	default:
+		return fmt.Errorf("invalid project source: %s", project.Source)
+	}
+}
+
+// resolveAWSTargets pushes aws settings declared on projects and deployments
+// down onto their resources, so each resource carries its full target
+func resolveAWSTargets(projects []types.ProjectDefinition) {
+	for _, project := range projects {
+		for _, deployment := range project.Deployments {
+			deploymentTarget := deployment.AWS.Inherit(project.AWS)
+
+			for i, resource := range deployment.Resources {
+				if resource.Type.IsAWS() {
+					deployment.Resources[i].AWS = resource.AWS.Inherit(deploymentTarget)
+				}
+			}
+		}
+	}
+}
+
+// resolveDiscoveryTargets gives each discovery rule the aws settings of the
+// project and deployment it adds resources to, as for declared resources
+func resolveDiscoveryTargets(config types.Config) {
+	if config.Discovery == nil {
+		return
+	}
+
+	for i, rule := range config.Discovery.Rules {
+		var parent *types.AWSTarget
+
+		project, found := findProject(config.Projects, rule.Project)
+		if found {
+			parent = project.AWS
+
+			deployment, found := findDeployment(project, rule.Deployment)
+			if found {
+				parent = deployment.AWS.Inherit(project.AWS)
+			}
+		}
+
+		config.Discovery.Rules[i].AWS = rule.AWS.Inherit(parent)
+	}
+}
+
+// resolveHealthRules gives each resource the health rules declared for its
+// type, where it doesn't set its own
+func resolveHealthRules(config types.Config) {
+	for _, project := range config.Projects {
+		for _, deployment := range project.Deployments {
+			for i, resource := range deployment.Resources {
+				rules, found := config.HealthRules[resource.Type]
+				if found {
+					deployment.Resources[i].Health = resource.Health.Inherit(&rules)
+				}
+			}
+		}
+	}
+}
+
+// usesDefaultCloudflareAccount reports whether any cloudflare resource relies
+// on the environment based default account
+func usesDefaultCloudflareAccount(config types.Config) bool {
+	for _, account := range config.CloudflareAccounts {
+		if account.Name == cloudflare.DefaultAccountName {
+			return false
+		}
+	}
+
+	for _, project := range config.Projects {
 		for _, deployment := range project.Deployments {
 			for _, resource := range deployment.Resources {
-				if strings.HasPrefix(string(resource.Type), "aws") {
-					requiredCredentials = append(requiredCredentials, "AWS_ACCESS_KEY_ID")
-					requiredCredentials = append(requiredCredentials, "AWS_SECRET_ACCESS_KEY")
-					requiredCredentials = append(requiredCredentials, "AWS_REGION")
-				} else if strings.HasPrefix(string(resource.Name), "cloudflare") {
-					requiredCredentials = append(requiredCredentials, "CLOUDFLARE_EMAIL")
-					requiredCredentials = append(requiredCredentials, "CLOUDFLARE_API_KEY")
-					requiredCredentials = append(requiredCredentials, "CLOUDFLARE_ACCOUNT_ID")
+				if resource.Type.IsCloudflare() && resource.CloudflareAccount == "" {
+					return true
 				}
 			}
 		}
 	}
 
-	return requiredCredentials
+	if config.Discovery != nil {
+		for _, rule := range config.Discovery.Rules {
+			if rule.CloudflareAccount == "" && slices.ContainsFunc(rule.Types, types.ResourceType.IsCloudflare) {
+				return true
+			}
+		}
+	}
+
+	return false
 }
 
-type Server struct {
-	Clients  common.Clients
-	Projects []types.ProjectDefinition
+// usesResourceType reports whether any resource in the config matches
+func usesResourceType(config types.Config, matches func(types.ResourceType) bool) bool {
+	for _, project := range config.Projects {
+		for _, deployment := range project.Deployments {
+			for _, resource := range deployment.Resources {
+				if matches(resource.Type) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
 }
 
-func main() {
+// getAWSEnvVars returns the variables needed to reach an aws target. A
+// profile supplies its own credentials and region
+func getAWSEnvVars(target *types.AWSTarget) []string {
+	if target == nil {
+		target = &types.AWSTarget{}
+	}
 
-	projectDefinitions, err := getProjectDefinitions()
+	if target.Profile != "" {
+		return []string{}
+	}
 
-	if err != nil {
-		fmt.Println("error getting project definitions", err)
-		os.Exit(1)
+	required := []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"}
+	if target.Region == "" {
+		required = append(required, "AWS_REGION")
 	}
 
-	requiredEnvVars := getRequiredEnvVars(projectDefinitions)
+	return required
+}
+
+func getRequiredEnvVars(config types.Config) []string {
+	requiredCredentials := []string{}
 
+	for _, project := range config.Projects {
+		for _, deployment := range project.Deployments {
+			for _, resource := range deployment.Resources {
+				switch resource.Type {
+				case types.VercelProjectResource:
+					requiredCredentials = append(requiredCredentials, vercel.TokenEnvVar(config.Vercel))
+				case types.NetlifySiteResource:
+					requiredCredentials = append(requiredCredentials, netlify.TokenEnvVar(config.Netlify))
+				case types.FlyAppResource:
+					requiredCredentials = append(requiredCredentials, fly.TokenEnvVar(config.Fly))
+				}
+
+				if resource.Type.IsAWS() {
+					requiredCredentials = append(requiredCredentials, getAWSEnvVars(resource.AWS)...)
+				}
+			}
+		}
+	}
+
+	if config.Discovery != nil {
+		for _, rule := range config.Discovery.Rules {
+			if slices.ContainsFunc(rule.Types, types.ResourceType.IsAWS) {
+				requiredCredentials = append(requiredCredentials, getAWSEnvVars(rule.AWS)...)
+			}
+		}
+	}
+
+	if usesResourceType(config, types.ResourceType.IsGitHub) && config.GitHub != nil && config.GitHub.TokenEnv != "" {
+		requiredCredentials = append(requiredCredentials, config.GitHub.TokenEnv)
+	}
+
+	if usesResourceType(config, types.ResourceType.IsAzure) {
+		requiredCredentials = append(requiredCredentials, azure.RequiredEnvVars(config.Azure)...)
+	}
+
+	// named accounts check their own variables when they're built
+	if usesDefaultCloudflareAccount(config) {
+		requiredCredentials = append(requiredCredentials, cloudflare.DefaultAccountEnvVars()...)
+	}
+
+	return requiredCredentials
+}
+
+// getImportEnvVars returns the variables needed to import project sources,
+// before any resources exist to require them
+func getImportEnvVars(config types.Config) []string {
+	requiredCredentials := []string{}
+
+	for _, project := range config.Projects {
+		if project.Source == "cloudformation-stack" {
+			requiredCredentials = append(requiredCredentials, getAWSEnvVars(project.AWS)...)
+		}
+	}
+
+	return requiredCredentials
+}
+
+func checkEnvVars(requiredEnvVars []string) error {
 	for _, requiredEnvVar := range requiredEnvVars {
 		_, found := os.LookupEnv(requiredEnvVar)
-
 		if !found {
-			fmt.Printf("required environment variable %s not found\n", requiredEnvVar)
-			os.Exit(1)
+			return fmt.Errorf("required environment variable %s not found", requiredEnvVar)
 		}
 	}
 
-	fmt.Println(projectDefinitions)
+	return nil
+}
+
+// getCloudflareAccounts builds and verifies every configured cloudflare
+// account, plus the default account when resources rely on it
+func getCloudflareAccounts(config types.Config) (map[string]cloudflare.Account, error) {
+	accounts := map[string]cloudflare.Account{}
+
+	for _, definition := range config.CloudflareAccounts {
+		account, err := cloudflare.NewAccount(definition)
+		if err != nil {
+			return nil, err
+		}
+
+		accounts[definition.Name] = account
+	}
+
+	if usesDefaultCloudflareAccount(config) {
+		account, err := cloudflare.NewDefaultAccount()
+		if err != nil {
+			return nil, err
+		}
+
+		accounts[cloudflare.DefaultAccountName] = account
+	}
+
+	for name, account := range accounts {
+		err := cloudflare.VerifyAccount(account)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify cloudflare account %s: %w", name, err)
+		}
+	}
+
+	return accounts, nil
+}
+
+type Server struct {
+	Clients  common.Clients
+	Projects *common.ProjectStore
+}
+
+func main() {
+
+	awsClients := aws.NewClientCache()
+
+	config, err := getConfig(awsClients)
 
-	ecsClient, err := aws.GetECSClient()
 	if err != nil {
-		log.Println("error getting ecs client", err)
+		fmt.Println("error getting config", err)
 		os.Exit(1)
 	}
 
-	rdsClient, err := aws.GetRDSClient()
+	projectDefinitions := config.Projects
+
+	// imported resources can need credentials of their own
+	err = checkEnvVars(getRequiredEnvVars(config))
 	if err != nil {
-		log.Println("error getting rds client", err)
+		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	elbClient, err := aws.GetELBClient()
+	fmt.Println(projectDefinitions)
+
+	cloudflareAccounts, err := getCloudflareAccounts(config)
 	if err != nil {
-		log.Println("error getting elb client", err)
+		log.Println("error getting cloudflare accounts", err)
 		os.Exit(1)
 	}
 
-	apigwClient, err := aws.GetAPIGatewayClient()
+	githubClient, err := github.NewClient(config.GitHub)
 	if err != nil {
-		log.Println("error getting apigw client", err)
+		log.Println("error getting github client", err)
 		os.Exit(1)
 	}
 
-	cloudflareClient := cloudflare.GetCloudflareClient()
+	// gcp credentials are only looked up when they're needed, since the
+	// application default credentials fail without any configured
+	var gcpClient *gcp.Client
+	if usesResourceType(config, types.ResourceType.IsGCP) {
+		gcpClient, err = gcp.NewClient(config.GCP)
+		if err != nil {
+			log.Println("error getting gcp client", err)
+			os.Exit(1)
+		}
+	}
 
 	clients := common.Clients{
-		EcsClient:        ecsClient,
-		RdsClient:        rdsClient,
-		ElbClient:        elbClient,
-		ApiGatewayClient: apigwClient,
-		CloudflareClient: cloudflareClient,
+		AWS:                awsClients,
+		CloudflareAccounts: cloudflareAccounts,
+		Kubernetes:         k8s.NewClientCache(),
+		Docker:             docker.NewClientCache(),
+		GitHub:             githubClient,
+		Vercel:             vercel.NewClient(config.Vercel),
+		Netlify:            netlify.NewClient(config.Netlify),
+		Fly:                fly.NewClient(config.Fly),
+		GCP:                gcpClient,
+		Azure:              azure.NewClient(config.Azure),
+	}
+
+	projects := common.NewProjectStore(projectDefinitions)
+
+	if config.Discovery != nil && len(config.Discovery.Rules) > 0 {
+		discoverer := discovery.NewDiscoverer(config, &clients, projects)
+
+		// the first evaluation happens before serving so discovered
+		// resources are available from the start
+		discoverer.Evaluate()
+		go discoverer.Run()
 	}
 
 	server := &Server{
 		Clients:  clients,
-		Projects: projectDefinitions,
+		Projects: projects,
 	}
 
-	collector := prometheus.NewBasicCollector(projectDefinitions, clients)
+	collector := prometheus.NewBasicCollector(projects, clients)
 	prometheus_client.MustRegister(collector)
 
 	router := http.NewServeMux()
@@ -392,8 +824,9 @@ func main() {
 
 	router.HandleFunc("/projects", server.GetProjectsHandler)
 	router.HandleFunc("/projects/{project}", server.GetProjectDefinitionHandler)
-	// router.HandleFunc("/projects/{project}/deployments/{deployment}/snapshot", server.GetDeploymentSnapshotHandler)
+	router.HandleFunc("/projects/{project}/deployments/{deployment}/snapshot", server.GetDeploymentSnapshotHandler)
 	router.HandleFunc("/projects/{project}/deployments/{deployment}/resources/{resource}/snapshot", server.GetResourceSnapshotHandler)
+	router.HandleFunc("/projects/{project}/deployments/{deployment}/resources/{resource}/history", server.GetResourceHistoryHandler)
 
 	configuredRouter := corsMiddleware(loggingMiddleware(router))
 
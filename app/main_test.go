@@ -0,0 +1,138 @@
+package main
+
+import (
+	"hermes/app/aws"
+	"hermes/app/types"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// unsetEnv clears a variable for the rest of the test, restoring it after
+func unsetEnv(t *testing.T, name string) {
+	t.Helper()
+
+	t.Setenv(name, "")
+	os.Unsetenv(name)
+}
+
+// useConfig writes projects.yaml to a temporary directory and moves into it
+func useConfig(t *testing.T, config string) {
+	t.Helper()
+
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.Chdir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(dir) })
+
+	err = os.WriteFile("projects.yaml", []byte(config), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetConfigProjectProfile(t *testing.T) {
+	for _, name := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_REGION"} {
+		unsetEnv(t, name)
+	}
+
+	useConfig(t, `
+projects:
+  - name: shop
+    aws:
+      profile: shop-prod
+      region: eu-west-1
+    deployments:
+      - name: production
+        resources:
+          - name: api
+            identifier: api-cluster
+            type: aws-ecs
+discovery:
+  rules:
+    - project: shop
+      deployment: production
+      types: [aws-lambda]
+      tags:
+        team: shop
+`)
+
+	config, err := getConfig(aws.NewClientCache())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	required := getRequiredEnvVars(config)
+	if len(required) != 0 {
+		t.Errorf("got required env vars %v, want none for a profile", required)
+	}
+}
+
+func TestGetImportEnvVars(t *testing.T) {
+	tests := []struct {
+		name    string
+		project types.ProjectDefinition
+		want    []string
+	}{
+		{
+			name:    "stack with a profile",
+			project: types.ProjectDefinition{Source: "cloudformation-stack", AWS: &types.AWSTarget{Profile: "shop-prod"}},
+			want:    []string{},
+		},
+		{
+			name:    "stack with a region",
+			project: types.ProjectDefinition{Source: "cloudformation-stack", AWS: &types.AWSTarget{Region: "eu-west-1"}},
+			want:    []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"},
+		},
+		{
+			name:    "stack without aws settings",
+			project: types.ProjectDefinition{Source: "cloudformation-stack"},
+			want:    []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_REGION"},
+		},
+		{
+			name: "declared resources only",
+			project: types.ProjectDefinition{
+				Deployments: []types.DeploymentDefinition{
+					{Resources: []types.ResourceDefinition{{Type: types.ECSResource}}},
+				},
+			},
+			want: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := getImportEnvVars(types.Config{Projects: []types.ProjectDefinition{test.project}})
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetConfigUnknownExpectPath(t *testing.T) {
+	useConfig(t, `
+projects:
+  - name: shop
+    deployments:
+      - name: production
+        resources:
+          - name: api
+            identifier: api-cluster
+            type: aws-ecs
+            expect:
+              task_count: 2
+`)
+
+	_, err := getConfig(aws.NewClientCache())
+	if err == nil || !strings.Contains(err.Error(), "task_count") {
+		t.Errorf("got error %v, want the unknown path task_count rejected", err)
+	}
+}
@@ -0,0 +1,98 @@
+package netlify
+
+import (
+	"encoding/json"
+	"errors"
+	"hermes/app/httpapi"
+	"hermes/app/types"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultBaseURL = "https://api.netlify.com/api/v1"
+
+const defaultTokenEnv = "NETLIFY_AUTH_TOKEN"
+
+const requestTimeout = 10 * time.Second
+
+var errNotFound = errors.New("netlify resource not found")
+
+// Client is a minimal Netlify REST client
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+// TokenEnvVar returns the environment variable the token is read from
+func TokenEnvVar(definition *types.NetlifyDefinition) string {
+	if definition != nil && definition.TokenEnv != "" {
+		return definition.TokenEnv
+	}
+
+	return defaultTokenEnv
+}
+
+// NewClient builds a client from the netlify section of the config, which
+// may be nil
+func NewClient(definition *types.NetlifyDefinition) *Client {
+	client := &Client{
+		httpClient: &http.Client{Timeout: requestTimeout},
+		baseURL:    defaultBaseURL,
+		token:      os.Getenv(TokenEnvVar(definition)),
+	}
+
+	if definition != nil && definition.BaseURL != "" {
+		client.baseURL = strings.TrimSuffix(definition.BaseURL, "/")
+	}
+
+	return client
+}
+
+// get fetches path with the given query, decoding the response into out.
+// A missing site or deploy returns errNotFound
+func (c *Client) get(path string, query url.Values, out any) error {
+	requestURL := c.baseURL + path
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return httpapi.ResponseError("netlify", resp, errorMessage)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// errorMessage returns the message netlify puts in an error body
+func errorMessage(body []byte) string {
+	var response struct {
+		Message string `json:"message"`
+	}
+
+	if json.Unmarshal(body, &response) != nil {
+		return ""
+	}
+
+	return response.Message
+}
@@ -0,0 +1,145 @@
+package netlify
+
+import (
+	"errors"
+	"fmt"
+	"hermes/app/types"
+	"net/url"
+	"time"
+)
+
+var _ types.ResourceStatus = SiteStatus{}
+
+type SiteStatus struct {
+	InstanceExists bool   `json:"exists"`
+	SiteID         string `json:"site_id"`
+	SiteUrl        string `json:"site_url"`
+	// the latest production deploy, empty when there are none
+	DeployID     string    `json:"deploy_id"`
+	DeployState  string    `json:"status"`
+	DeployUrl    string    `json:"url"`
+	ErrorMessage string    `json:"error_message,omitempty"`
+	Branch       string    `json:"branch"`
+	CommitRef    string    `json:"commit_ref"`
+	CreatedAt    time.Time `json:"created_at"`
+	// the deploy the site is serving, which can be older than the latest
+	// one. Empty until a deploy has been published
+	PublishedDeployID string `json:"published_deploy_id"`
+}
+
+func (s SiteStatus) IsResourceStatus() {}
+
+// GetHealth judges a site on its latest production deploy. A deploy that
+// is in progress or failed only replaces the published one once it is
+// ready, so the site is healthy or degraded while it has a published deploy,
+// and unhealthy before its first one
+func (s SiteStatus) GetHealth() types.Health {
+	switch s.DeployState {
+	case "ready":
+		return types.Healthy()
+	case "":
+		if !s.InstanceExists {
+			return types.Unhealthy("not found")
+		}
+
+		return types.Unhealthy("no production deploys")
+	}
+
+	if s.PublishedDeployID == "" {
+		return types.Unhealthy(fmt.Sprintf("no published deploy, latest is %s", s.DeployState))
+	}
+
+	switch s.DeployState {
+	case "new", "enqueued", "building", "uploading", "uploaded", "preparing", "prepared", "processing", "processed":
+		return types.Healthy()
+	case "error":
+		if s.ErrorMessage != "" {
+			return types.Degraded(fmt.Sprintf("latest production deploy failed: %s", s.ErrorMessage))
+		}
+
+		return types.Degraded("latest production deploy failed")
+	default:
+		return types.Degraded(fmt.Sprintf("latest production deploy is %s", s.DeployState))
+	}
+}
+
+func (s SiteStatus) Exists() bool {
+	return s.InstanceExists
+}
+
+func (s SiteStatus) GetStatusString() string {
+	if s.DeployState == "" {
+		return "no-deploys"
+	}
+
+	return s.DeployState
+}
+
+// GetSiteStatus reads the latest production deploy of a site, identified by
+// its id or domain (name.netlify.app)
+func GetSiteStatus(client *Client, identifier string) (SiteStatus, error) {
+	var site struct {
+		ID              string `json:"id"`
+		SSLURL          string `json:"ssl_url"`
+		PublishedDeploy *struct {
+			ID string `json:"id"`
+		} `json:"published_deploy"`
+	}
+
+	err := client.get("/sites/"+url.PathEscape(identifier), url.Values{}, &site)
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			return SiteStatus{
+				InstanceExists: false,
+			}, nil
+		}
+
+		return SiteStatus{}, err
+	}
+
+	var deploys []struct {
+		ID           string    `json:"id"`
+		State        string    `json:"state"`
+		ErrorMessage string    `json:"error_message"`
+		Branch       string    `json:"branch"`
+		CommitRef    string    `json:"commit_ref"`
+		DeploySSLURL string    `json:"deploy_ssl_url"`
+		CreatedAt    time.Time `json:"created_at"`
+	}
+
+	err = client.get(
+		"/sites/"+url.PathEscape(site.ID)+"/deploys",
+		url.Values{
+			"production": {"true"},
+			"per_page":   {"1"},
+		},
+		&deploys,
+	)
+	if err != nil {
+		return SiteStatus{}, err
+	}
+
+	status := SiteStatus{
+		InstanceExists: true,
+		SiteID:         site.ID,
+		SiteUrl:        site.SSLURL,
+	}
+
+	if site.PublishedDeploy != nil {
+		status.PublishedDeployID = site.PublishedDeploy.ID
+	}
+
+	if len(deploys) > 0 {
+		deploy := deploys[0]
+
+		status.DeployID = deploy.ID
+		status.DeployState = deploy.State
+		status.DeployUrl = deploy.DeploySSLURL
+		status.ErrorMessage = deploy.ErrorMessage
+		status.Branch = deploy.Branch
+		status.CommitRef = deploy.CommitRef
+		status.CreatedAt = deploy.CreatedAt
+	}
+
+	return status, nil
+}
@@ -0,0 +1,147 @@
+package netlify
+
+import (
+	"fmt"
+	"hermes/app/types"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSiteStatusGetHealth(t *testing.T) {
+	tests := []struct {
+		name   string
+		status SiteStatus
+		want   types.HealthState
+	}{
+		{"not found", SiteStatus{}, types.HealthUnhealthy},
+		{"ready", SiteStatus{InstanceExists: true, DeployState: "ready"}, types.HealthHealthy},
+		{"no deploys", SiteStatus{InstanceExists: true}, types.HealthUnhealthy},
+		{
+			"first deploy building",
+			SiteStatus{InstanceExists: true, DeployState: "building"},
+			types.HealthUnhealthy,
+		},
+		{
+			"building over a published deploy",
+			SiteStatus{InstanceExists: true, DeployState: "building", PublishedDeployID: "abc"},
+			types.HealthHealthy,
+		},
+		{
+			"failed over a published deploy",
+			SiteStatus{InstanceExists: true, DeployState: "error", PublishedDeployID: "abc"},
+			types.HealthDegraded,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.status.GetHealth()
+			if got.State != test.want {
+				t.Errorf("got %s (%s), want %s", got.State, got.Reason, test.want)
+			}
+		})
+	}
+}
+
+func newStubNetlify(t *testing.T) *Client {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sites/{site}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"code": 401, "message": "Access Denied"}`)
+			return
+		}
+
+		switch r.PathValue("site") {
+		case "shop.netlify.app":
+			fmt.Fprint(w, `{"id": "site-1", "ssl_url": "https://shop.netlify.app", "published_deploy": {"id": "deploy-0"}}`)
+		case "flaky":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, "upstream connect error")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"code": 404, "message": "Not Found"}`)
+		}
+	})
+	mux.HandleFunc("/sites/site-1/deploys", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("production") != "true" {
+			http.Error(w, "unexpected query", http.StatusBadRequest)
+			return
+		}
+
+		fmt.Fprint(w, `[{
+			"id": "deploy-1",
+			"state": "error",
+			"error_message": "Build script returned non-zero exit code: 2",
+			"branch": "main",
+			"commit_ref": "abc123",
+			"deploy_ssl_url": "https://deploy-1--shop.netlify.app",
+			"created_at": "2024-01-01T00:00:00Z"
+		}]`)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	t.Setenv("HERMES_TEST_NETLIFY_TOKEN", "token")
+
+	return NewClient(&types.NetlifyDefinition{BaseURL: server.URL, TokenEnv: "HERMES_TEST_NETLIFY_TOKEN"})
+}
+
+func TestGetSiteStatus(t *testing.T) {
+	client := newStubNetlify(t)
+
+	status, err := GetSiteStatus(client, "shop.netlify.app")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := SiteStatus{
+		InstanceExists:    true,
+		SiteID:            "site-1",
+		SiteUrl:           "https://shop.netlify.app",
+		DeployID:          "deploy-1",
+		DeployState:       "error",
+		DeployUrl:         "https://deploy-1--shop.netlify.app",
+		ErrorMessage:      "Build script returned non-zero exit code: 2",
+		Branch:            "main",
+		CommitRef:         "abc123",
+		CreatedAt:         time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		PublishedDeployID: "deploy-0",
+	}
+
+	if !reflect.DeepEqual(status, want) {
+		t.Errorf("got %+v, want %+v", status, want)
+	}
+
+	if health := status.GetHealth(); health.State != types.HealthDegraded {
+		t.Errorf("got health %s (%s), want degraded", health.State, health.Reason)
+	}
+}
+
+func TestGetSiteStatusErrors(t *testing.T) {
+	client := newStubNetlify(t)
+
+	status, err := GetSiteStatus(client, "missing")
+	if err != nil || status.InstanceExists {
+		t.Errorf("got (%+v, %v), want a missing site", status, err)
+	}
+
+	_, err = GetSiteStatus(client, "flaky")
+	want := "netlify api returned 503: upstream connect error"
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+
+	client.token = "wrong"
+	_, err = GetSiteStatus(client, "shop.netlify.app")
+	want = "netlify api returned 401: Access Denied"
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+}
@@ -0,0 +1,108 @@
+package probe
+
+import (
+	"fmt"
+	"hermes/app/types"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultDatabaseTimeout = 5 * time.Second
+
+const (
+	primaryRole = "primary"
+	replicaRole = "replica"
+)
+
+var _ types.ResourceStatus = DatabaseStatus{}
+
+// DatabaseStatus is shared by the postgres, mysql and redis probes
+type DatabaseStatus struct {
+	Reachable bool          `json:"exists"`
+	Latency   time.Duration `json:"latency"`
+	Version   string        `json:"version"`
+	// primary or replica, as reported by the server
+	Role  string `json:"role"`
+	Error string `json:"error,omitempty"`
+}
+
+func (d DatabaseStatus) IsResourceStatus() {}
+
+func (d DatabaseStatus) GetHealth() types.Health {
+	if !d.Reachable {
+		return unreachable(d.Error)
+	}
+
+	return types.Healthy()
+}
+
+func (d DatabaseStatus) Exists() bool {
+	return d.Reachable
+}
+
+func (d DatabaseStatus) GetStatusString() string {
+	if !d.Reachable {
+		return "unreachable"
+	}
+
+	return "ok"
+}
+
+func getDatabaseTimeout(options *types.DatabaseProbeOptions) time.Duration {
+	if options != nil && options.Timeout != 0 {
+		return options.Timeout
+	}
+
+	return defaultDatabaseTimeout
+}
+
+// lookupCredentialEnv reads a credential from the named environment variable,
+// treating an unset or empty variable as a mistake rather than connecting
+// without it
+func lookupCredentialEnv(name string) (string, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return "", fmt.Errorf("database credential environment variable %s is unset or empty", name)
+	}
+
+	return value, nil
+}
+
+// getDatabaseCredentials reads the username and password for a probe. The
+// password is read from the named environment variable or file, so it never
+// appears in the config
+func getDatabaseCredentials(options *types.DatabaseProbeOptions) (string, string, error) {
+	if options == nil {
+		return "", "", nil
+	}
+
+	username := options.Username
+	if options.UsernameEnv != "" {
+		var err error
+		username, err = lookupCredentialEnv(options.UsernameEnv)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	if options.PasswordEnv != "" {
+		password, err := lookupCredentialEnv(options.PasswordEnv)
+		if err != nil {
+			return "", "", err
+		}
+
+		return username, password, nil
+	}
+
+	if options.PasswordFile != "" {
+		password, err := os.ReadFile(options.PasswordFile)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read database password file: %w", err)
+		}
+
+		return username, strings.TrimSpace(string(password)), nil
+	}
+
+	return username, "", nil
+}
@@ -0,0 +1,77 @@
+package probe
+
+import (
+	"hermes/app/types"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetDatabaseCredentials(t *testing.T) {
+	t.Setenv("HERMES_TEST_DB_USER", "monitor")
+	t.Setenv("HERMES_TEST_DB_PASSWORD", "hunter2")
+	t.Setenv("HERMES_TEST_DB_EMPTY", "")
+
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	err := os.WriteFile(passwordFile, []byte("from-file\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		options      *types.DatabaseProbeOptions
+		wantUsername string
+		wantPassword string
+		wantErr      bool
+	}{
+		{
+			name: "no options",
+		},
+		{
+			name:         "password from env",
+			options:      &types.DatabaseProbeOptions{UsernameEnv: "HERMES_TEST_DB_USER", PasswordEnv: "HERMES_TEST_DB_PASSWORD"},
+			wantUsername: "monitor",
+			wantPassword: "hunter2",
+		},
+		{
+			name:         "password from file",
+			options:      &types.DatabaseProbeOptions{Username: "monitor", PasswordFile: passwordFile},
+			wantUsername: "monitor",
+			wantPassword: "from-file",
+		},
+		{
+			name:    "unset password env",
+			options: &types.DatabaseProbeOptions{Username: "monitor", PasswordEnv: "HERMES_TEST_DB_UNSET"},
+			wantErr: true,
+		},
+		{
+			name:    "empty password env",
+			options: &types.DatabaseProbeOptions{Username: "monitor", PasswordEnv: "HERMES_TEST_DB_EMPTY"},
+			wantErr: true,
+		},
+		{
+			name:    "unset username env",
+			options: &types.DatabaseProbeOptions{UsernameEnv: "HERMES_TEST_DB_UNSET"},
+			wantErr: true,
+		},
+		{
+			name:    "missing password file",
+			options: &types.DatabaseProbeOptions{PasswordFile: filepath.Join(t.TempDir(), "missing")},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			username, password, err := getDatabaseCredentials(test.options)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error %t", err, test.wantErr)
+			}
+
+			if username != test.wantUsername || password != test.wantPassword {
+				t.Errorf("got (%q, %q), want (%q, %q)", username, password, test.wantUsername, test.wantPassword)
+			}
+		})
+	}
+}
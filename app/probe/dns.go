@@ -0,0 +1,187 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hermes/app/types"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+const defaultDNSTimeout = 5 * time.Second
+
+var _ types.ResourceStatus = DNSStatus{}
+
+type DNSStatus struct {
+	Resolved bool          `json:"exists"`
+	A        []string      `json:"a"`
+	AAAA     []string      `json:"aaaa"`
+	CNAME    string        `json:"cname"`
+	Latency  time.Duration `json:"latency"`
+	Error    string        `json:"error,omitempty"`
+	Failures []string      `json:"failures"`
+}
+
+func (d DNSStatus) IsResourceStatus() {}
+
+func (d DNSStatus) GetHealth() types.Health {
+	if !d.Resolved {
+		if d.Error == "" {
+			return types.Unhealthy("unresolved")
+		}
+
+		return types.Unhealthy(fmt.Sprintf("unresolved: %s", d.Error))
+	}
+
+	if len(d.Failures) > 0 {
+		return types.Unhealthy(strings.Join(d.Failures, ", "))
+	}
+
+	return types.Healthy()
+}
+
+func (d DNSStatus) Exists() bool {
+	return d.Resolved
+}
+
+func (d DNSStatus) GetStatusString() string {
+	if !d.Resolved {
+		return "unresolved"
+	}
+
+	if len(d.Failures) > 0 {
+		return "mismatch"
+	}
+
+	return "ok"
+}
+
+func newResolver(address string, timeout time.Duration) *net.Resolver {
+	if address == "" {
+		return net.DefaultResolver
+	}
+
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "53")
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			dialer := net.Dialer{
+				Timeout: timeout,
+			}
+
+			return dialer.DialContext(ctx, network, address)
+		},
+	}
+}
+
+func normalizeDNSName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(name), ".")
+}
+
+// normalizeIP rewrites an address in its canonical form, so that 2001:DB8::1
+// and 2001:db8:0:0:0:0:0:1 compare equal. Anything that isn't an address is
+// returned unchanged
+func normalizeIP(address string) string {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return address
+	}
+
+	return ip.String()
+}
+
+// findMissingAnswers returns the expected answers that weren't returned. Only
+// presence is checked, so round robin records with extra answers still pass
+func findMissingAnswers(expected []string, answers []string) []string {
+	normalizedAnswers := []string{}
+	for _, answer := range answers {
+		normalizedAnswers = append(normalizedAnswers, normalizeIP(answer))
+	}
+
+	missing := []string{}
+	for _, answer := range expected {
+		if !slices.Contains(normalizedAnswers, normalizeIP(answer)) {
+			missing = append(missing, answer)
+		}
+	}
+
+	return missing
+}
+
+// GetDNSStatus resolves name against the configured resolver, or the system
+// resolver when none is given, and compares the answers with any expected
+func GetDNSStatus(name string, options *types.DNSProbeOptions) (DNSStatus, error) {
+	probeOptions := types.DNSProbeOptions{}
+	if options != nil {
+		probeOptions = *options
+	}
+
+	timeout := probeOptions.Timeout
+	if timeout == 0 {
+		timeout = defaultDNSTimeout
+	}
+
+	resolver := newResolver(probeOptions.Resolver, timeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	addrs, err := resolver.LookupIPAddr(ctx, name)
+	latency := time.Since(start)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) {
+			return DNSStatus{
+				Resolved: false,
+				Error:    dnsErr.Error(),
+				Failures: []string{"lookup failed"},
+			}, nil
+		}
+
+		return DNSStatus{}, err
+	}
+
+	status := DNSStatus{
+		Resolved: true,
+		A:        []string{},
+		AAAA:     []string{},
+		Latency:  latency,
+		Failures: []string{},
+	}
+
+	for _, addr := range addrs {
+		if addr.IP.To4() != nil {
+			status.A = append(status.A, addr.IP.String())
+		} else {
+			status.AAAA = append(status.AAAA, addr.IP.String())
+		}
+	}
+
+	cname, err := resolver.LookupCNAME(ctx, name)
+	if err == nil && normalizeDNSName(cname) != normalizeDNSName(name) {
+		status.CNAME = normalizeDNSName(cname)
+	}
+
+	if missing := findMissingAnswers(probeOptions.ExpectedA, status.A); len(missing) > 0 {
+		status.Failures = append(status.Failures, fmt.Sprintf("a: missing %v", missing))
+	}
+
+	if missing := findMissingAnswers(probeOptions.ExpectedAAAA, status.AAAA); len(missing) > 0 {
+		status.Failures = append(status.Failures, fmt.Sprintf("aaaa: missing %v", missing))
+	}
+
+	if probeOptions.ExpectedCNAME != "" && normalizeDNSName(probeOptions.ExpectedCNAME) != status.CNAME {
+		status.Failures = append(status.Failures,
+			fmt.Sprintf("cname: expected %s, found %s", normalizeDNSName(probeOptions.ExpectedCNAME), status.CNAME),
+		)
+	}
+
+	return status, nil
+}
@@ -0,0 +1,73 @@
+package probe
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFindMissingAnswers(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected []string
+		answers  []string
+		want     []string
+	}{
+		{
+			name:     "all present",
+			expected: []string{"192.0.2.1"},
+			answers:  []string{"192.0.2.1", "192.0.2.2"},
+			want:     []string{},
+		},
+		{
+			name:     "one missing",
+			expected: []string{"192.0.2.1", "192.0.2.3"},
+			answers:  []string{"192.0.2.1", "192.0.2.2"},
+			want:     []string{"192.0.2.3"},
+		},
+		{
+			name:     "nothing expected",
+			expected: nil,
+			answers:  []string{"192.0.2.1"},
+			want:     []string{},
+		},
+		{
+			name:     "expanded ipv6",
+			expected: []string{"2001:0db8:0000:0000:0000:0000:0000:0001"},
+			answers:  []string{"2001:db8::1"},
+			want:     []string{},
+		},
+		{
+			name:     "uppercase ipv6",
+			expected: []string{"2001:DB8::1"},
+			answers:  []string{"2001:db8::1"},
+			want:     []string{},
+		},
+		{
+			name:     "ipv4 mapped ipv6",
+			expected: []string{"::ffff:192.0.2.1"},
+			answers:  []string{"192.0.2.1"},
+			want:     []string{},
+		},
+		{
+			name:     "missing ipv6 keeps its written form",
+			expected: []string{"2001:DB8::2"},
+			answers:  []string{"2001:db8::1"},
+			want:     []string{"2001:DB8::2"},
+		},
+		{
+			name:     "not an address",
+			expected: []string{"example.com"},
+			answers:  []string{"192.0.2.1"},
+			want:     []string{"example.com"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := findMissingAnswers(test.expected, test.answers)
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
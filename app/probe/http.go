@@ -0,0 +1,220 @@
+package probe
+
+import (
+	"encoding/json"
+	"fmt"
+	"hermes/app/types"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+const (
+	defaultHTTPTimeout = 10 * time.Second
+
+	// bodies beyond this size are truncated before matching
+	maxHTTPBodyBytes = 1 << 20
+)
+
+var _ types.ResourceStatus = HTTPStatus{}
+
+type HTTPStatus struct {
+	Reachable  bool          `json:"exists"`
+	StatusCode int           `json:"status_code"`
+	FinalURL   string        `json:"final_url"`
+	Latency    time.Duration `json:"latency"`
+	Error      string        `json:"error,omitempty"`
+	Failures   []string      `json:"failures"`
+}
+
+func (h HTTPStatus) IsResourceStatus() {}
+
+func (h HTTPStatus) GetHealth() types.Health {
+	if !h.Reachable {
+		return unreachable(h.Error)
+	}
+
+	if len(h.Failures) > 0 {
+		return types.Unhealthy(strings.Join(h.Failures, ", "))
+	}
+
+	return types.Healthy()
+}
+
+func (h HTTPStatus) Exists() bool {
+	return h.Reachable
+}
+
+func (h HTTPStatus) GetStatusString() string {
+	if !h.Reachable {
+		return "unreachable"
+	}
+
+	if len(h.Failures) > 0 {
+		return "failing"
+	}
+
+	return "ok"
+}
+
+func isExpectedStatus(statusCode int, expected []int) bool {
+	if len(expected) == 0 {
+		return statusCode >= 200 && statusCode < 300
+	}
+
+	return slices.Contains(expected, statusCode)
+}
+
+// normalizeJSONValue round trips a value decoded from yaml through json, so
+// it has the same shape as values read out of a response body
+func normalizeJSONValue(value any) (any, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var normalized any
+	err = json.Unmarshal(raw, &normalized)
+
+	return normalized, err
+}
+
+func checkJSONAssertions(body []byte, assertions map[string]any) []string {
+	failures := []string{}
+
+	if !gjson.ValidBytes(body) {
+		return append(failures, "body is not valid json")
+	}
+
+	paths := []string{}
+	for path := range assertions {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		expected := assertions[path]
+		result := gjson.GetBytes(body, path)
+		if !result.Exists() {
+			failures = append(failures, fmt.Sprintf("json %s: not found", path))
+			continue
+		}
+
+		normalized, err := normalizeJSONValue(expected)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("json %s: invalid expected value: %s", path, err))
+			continue
+		}
+
+		if !reflect.DeepEqual(result.Value(), normalized) {
+			failures = append(failures, fmt.Sprintf("json %s: expected %v, found %v", path, normalized, result.Value()))
+		}
+	}
+
+	return failures
+}
+
+// GetHTTPStatus makes a synthetic request against url and checks the
+// response against the resource's options. An unreachable url is reported
+// through the status rather than as an error
+func GetHTTPStatus(url string, options *types.HTTPProbeOptions) (HTTPStatus, error) {
+	probeOptions := types.HTTPProbeOptions{}
+	if options != nil {
+		probeOptions = *options
+	}
+
+	timeout := probeOptions.Timeout
+	if timeout == 0 {
+		timeout = defaultHTTPTimeout
+	}
+
+	method := probeOptions.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	client := &http.Client{
+		Timeout: timeout,
+	}
+
+	if probeOptions.FollowRedirects != nil && !*probeOptions.FollowRedirects {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	var bodyRegex *regexp.Regexp
+	if probeOptions.BodyRegex != "" {
+		var err error
+		bodyRegex, err = regexp.Compile(probeOptions.BodyRegex)
+		if err != nil {
+			return HTTPStatus{}, err
+		}
+	}
+
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return HTTPStatus{}, err
+	}
+
+	for key, value := range probeOptions.Headers {
+		req.Header.Set(key, value)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return HTTPStatus{
+			Reachable: false,
+			Error:     err.Error(),
+			Failures:  []string{"request failed"},
+		}, nil
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPBodyBytes))
+	latency := time.Since(start)
+	if err != nil {
+		return HTTPStatus{
+			Reachable: false,
+			Error:     err.Error(),
+			Failures:  []string{"failed to read body"},
+		}, nil
+	}
+
+	failures := []string{}
+
+	if !isExpectedStatus(resp.StatusCode, probeOptions.ExpectedStatus) {
+		failures = append(failures, fmt.Sprintf("unexpected status code %d", resp.StatusCode))
+	}
+
+	if probeOptions.MaxLatency != 0 && latency > probeOptions.MaxLatency {
+		failures = append(failures, fmt.Sprintf("latency %s exceeds %s", latency, probeOptions.MaxLatency))
+	}
+
+	if probeOptions.BodyContains != "" && !strings.Contains(string(body), probeOptions.BodyContains) {
+		failures = append(failures, fmt.Sprintf("body does not contain %q", probeOptions.BodyContains))
+	}
+
+	if bodyRegex != nil && !bodyRegex.Match(body) {
+		failures = append(failures, fmt.Sprintf("body does not match %s", probeOptions.BodyRegex))
+	}
+
+	if len(probeOptions.JSON) > 0 {
+		failures = append(failures, checkJSONAssertions(body, probeOptions.JSON)...)
+	}
+
+	return HTTPStatus{
+		Reachable:  true,
+		StatusCode: resp.StatusCode,
+		FinalURL:   resp.Request.URL.String(),
+		Latency:    latency,
+		Failures:   failures,
+	}, nil
+}
@@ -0,0 +1,248 @@
+package probe
+
+import (
+	"fmt"
+	"hermes/app/types"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newHTTPTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "all systems operational, build 1234")
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not here", http.StatusNotFound)
+	})
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"status": "up", "checks": {"db": {"status": "up", "connections": 3}}, "replicas": [1, 2]}`)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		fmt.Fprint(w, "done")
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/ok", http.StatusFound)
+	})
+	mux.HandleFunc("/headers", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestGetHTTPStatus(t *testing.T) {
+	server := newHTTPTestServer(t)
+	noRedirects := false
+
+	tests := []struct {
+		name         string
+		path         string
+		options      *types.HTTPProbeOptions
+		wantStatus   int
+		wantFailures int
+		wantFinal    string
+	}{
+		{
+			name:       "default options",
+			path:       "/ok",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:         "unexpected status code",
+			path:         "/missing",
+			wantStatus:   http.StatusNotFound,
+			wantFailures: 1,
+		},
+		{
+			name:       "expected non 2xx status code",
+			path:       "/missing",
+			options:    &types.HTTPProbeOptions{ExpectedStatus: []int{404}},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "body contains and regex match",
+			path:       "/ok",
+			options:    &types.HTTPProbeOptions{BodyContains: "operational", BodyRegex: `build \d+`},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:         "body regex doesn't match",
+			path:         "/ok",
+			options:      &types.HTTPProbeOptions{BodyRegex: `^degraded`},
+			wantStatus:   http.StatusOK,
+			wantFailures: 1,
+		},
+		{
+			name: "json assertions pass",
+			path: "/health",
+			options: &types.HTTPProbeOptions{JSON: map[string]any{
+				"status":                "up",
+				"checks.db.status":      "up",
+				"checks.db.connections": 3,
+				"replicas":              []any{1, 2},
+				"checks.db":             map[string]any{"status": "up", "connections": 3},
+			}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "json assertions fail",
+			path: "/health",
+			options: &types.HTTPProbeOptions{JSON: map[string]any{
+				"status":           "down",
+				"checks.cache":     "up",
+				"checks.db.status": "up",
+			}},
+			wantStatus:   http.StatusOK,
+			wantFailures: 2,
+		},
+		{
+			name:         "json assertions against a non json body",
+			path:         "/ok",
+			options:      &types.HTTPProbeOptions{JSON: map[string]any{"status": "up"}},
+			wantStatus:   http.StatusOK,
+			wantFailures: 1,
+		},
+		{
+			name:         "latency over the maximum",
+			path:         "/slow",
+			options:      &types.HTTPProbeOptions{MaxLatency: time.Millisecond},
+			wantStatus:   http.StatusOK,
+			wantFailures: 1,
+		},
+		{
+			name:       "latency under the maximum",
+			path:       "/slow",
+			options:    &types.HTTPProbeOptions{MaxLatency: 5 * time.Second},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "redirects followed by default",
+			path:       "/redirect",
+			wantStatus: http.StatusOK,
+			wantFinal:  "/ok",
+		},
+		{
+			name:       "redirects not followed",
+			path:       "/redirect",
+			options:    &types.HTTPProbeOptions{FollowRedirects: &noRedirects, ExpectedStatus: []int{302}},
+			wantStatus: http.StatusFound,
+			wantFinal:  "/redirect",
+		},
+		{
+			name: "method and headers",
+			path: "/headers",
+			options: &types.HTTPProbeOptions{
+				Method:  http.MethodHead,
+				Headers: map[string]string{"Authorization": "Bearer token"},
+			},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, err := GetHTTPStatus(server.URL+test.path, test.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !status.Reachable {
+				t.Fatalf("unreachable: %s", status.Error)
+			}
+
+			if status.StatusCode != test.wantStatus {
+				t.Errorf("got status code %d, want %d", status.StatusCode, test.wantStatus)
+			}
+
+			if len(status.Failures) != test.wantFailures {
+				t.Errorf("got failures %q, want %d", status.Failures, test.wantFailures)
+			}
+
+			if test.wantFinal != "" && !strings.HasSuffix(status.FinalURL, test.wantFinal) {
+				t.Errorf("got final url %s, want %s", status.FinalURL, test.wantFinal)
+			}
+
+			if status.Latency <= 0 {
+				t.Errorf("got latency %s, want a positive duration", status.Latency)
+			}
+
+			wantHealth := types.HealthHealthy
+			if test.wantFailures > 0 {
+				wantHealth = types.HealthUnhealthy
+			}
+
+			if health := status.GetHealth(); health.State != wantHealth {
+				t.Errorf("got health %s, want %s", health.State, wantHealth)
+			}
+		})
+	}
+}
+
+func TestGetHTTPStatusUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	status, err := GetHTTPStatus(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status.Reachable || status.Error == "" {
+		t.Errorf("got reachable %t with error %q, want an unreachable status", status.Reachable, status.Error)
+	}
+
+	if health := status.GetHealth(); health.State != types.HealthUnhealthy {
+		t.Errorf("got health %s, want unhealthy", health.State)
+	}
+}
+
+func TestGetHTTPStatusInvalidRegex(t *testing.T) {
+	server := newHTTPTestServer(t)
+
+	_, err := GetHTTPStatus(server.URL+"/ok", &types.HTTPProbeOptions{BodyRegex: "("})
+	if err == nil {
+		t.Error("expected an error for an invalid body regex")
+	}
+}
+
+func TestCheckJSONAssertionsOrder(t *testing.T) {
+	body := []byte(`{"status": "degraded", "checks": {"db": "down", "cache": "down"}, "version": 2}`)
+	assertions := map[string]any{
+		"version":      3,
+		"status":       "ok",
+		"checks.db":    "up",
+		"checks.cache": "up",
+		"checks.queue": "up",
+	}
+
+	want := []string{
+		"json checks.cache: expected up, found down",
+		"json checks.db: expected up, found down",
+		"json checks.queue: not found",
+		"json status: expected ok, found degraded",
+		"json version: expected 3, found 2",
+	}
+
+	// map iteration order varies, so check that repeated runs agree
+	for range 10 {
+		failures := checkJSONAssertions(body, assertions)
+		if !slices.Equal(failures, want) {
+			t.Fatalf("got %q, want %q", failures, want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package probe
+
+import (
+	"context"
+	"database/sql"
+	"hermes/app/types"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// GetMySQLStatus connects to the mysql server at address (host:port), runs a
+// trivial query and reports the server's role. A server with read_only set
+// is reported as a replica
+func GetMySQLStatus(address string, options *types.DatabaseProbeOptions) (DatabaseStatus, error) {
+	username, password, err := getDatabaseCredentials(options)
+	if err != nil {
+		return DatabaseStatus{}, err
+	}
+
+	timeout := getDatabaseTimeout(options)
+
+	config := mysql.NewConfig()
+	config.Net = "tcp"
+	config.Addr = address
+	config.User = username
+	config.Passwd = password
+	config.Timeout = timeout
+	config.TLSConfig = "preferred"
+	if options != nil {
+		config.DBName = options.Database
+
+		if options.TLS {
+			config.TLSConfig = "true"
+		}
+	}
+
+	connector, err := mysql.NewConnector(config)
+	if err != nil {
+		return DatabaseStatus{}, err
+	}
+
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	var version string
+	var readOnly bool
+	err = db.QueryRowContext(ctx, "SELECT VERSION(), @@global.read_only").Scan(&version, &readOnly)
+	latency := time.Since(start)
+	if err != nil {
+		return DatabaseStatus{
+			Reachable: false,
+			Error:     err.Error(),
+		}, nil
+	}
+
+	role := primaryRole
+	if readOnly {
+		role = replicaRole
+	}
+
+	return DatabaseStatus{
+		Reachable: true,
+		Latency:   latency,
+		Version:   version,
+		Role:      role,
+	}, nil
+}
@@ -0,0 +1,78 @@
+package probe
+
+import (
+	"context"
+	"hermes/app/types"
+	"net/url"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetPostgresStatus connects to the postgres server at address (host:port),
+// runs a trivial query and reports whether the server is in recovery
+func GetPostgresStatus(address string, options *types.DatabaseProbeOptions) (DatabaseStatus, error) {
+	username, password, err := getDatabaseCredentials(options)
+	if err != nil {
+		return DatabaseStatus{}, err
+	}
+
+	connURL := url.URL{
+		Scheme: "postgres",
+		Host:   address,
+		User:   url.UserPassword(username, password),
+	}
+
+	query := url.Values{}
+	query.Set("sslmode", "prefer")
+	if options != nil {
+		connURL.Path = "/" + options.Database
+
+		if options.TLS {
+			query.Set("sslmode", "require")
+		}
+	}
+	connURL.RawQuery = query.Encode()
+
+	config, err := pgx.ParseConfig(connURL.String())
+	if err != nil {
+		return DatabaseStatus{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), getDatabaseTimeout(options))
+	defer cancel()
+
+	start := time.Now()
+	conn, err := pgx.ConnectConfig(ctx, config)
+	if err != nil {
+		return DatabaseStatus{
+			Reachable: false,
+			Error:     err.Error(),
+		}, nil
+	}
+	defer conn.Close(context.Background())
+
+	var version string
+	var inRecovery bool
+	err = conn.QueryRow(ctx, "SELECT current_setting('server_version'), pg_is_in_recovery()").
+		Scan(&version, &inRecovery)
+	latency := time.Since(start)
+	if err != nil {
+		return DatabaseStatus{
+			Reachable: false,
+			Error:     err.Error(),
+		}, nil
+	}
+
+	role := primaryRole
+	if inRecovery {
+		role = replicaRole
+	}
+
+	return DatabaseStatus{
+		Reachable: true,
+		Latency:   latency,
+		Version:   version,
+		Role:      role,
+	}, nil
+}
@@ -0,0 +1,89 @@
+package probe
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"hermes/app/types"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// parseRedisInfo reads the key:value lines of an INFO reply
+func parseRedisInfo(info string) map[string]string {
+	fields := map[string]string{}
+
+	scanner := bufio.NewScanner(strings.NewReader(info))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if found {
+			fields[key] = value
+		}
+	}
+
+	return fields
+}
+
+// GetRedisStatus connects to the redis server at address (host:port), sends
+// a PING and reads the server version and replication role
+func GetRedisStatus(address string, options *types.DatabaseProbeOptions) (DatabaseStatus, error) {
+	username, password, err := getDatabaseCredentials(options)
+	if err != nil {
+		return DatabaseStatus{}, err
+	}
+
+	timeout := getDatabaseTimeout(options)
+
+	redisOptions := &redis.Options{
+		Addr:         address,
+		Username:     username,
+		Password:     password,
+		DialTimeout:  timeout,
+		ReadTimeout:  timeout,
+		WriteTimeout: timeout,
+		MaxRetries:   -1,
+	}
+	if options != nil && options.TLS {
+		redisOptions.TLSConfig = &tls.Config{}
+	}
+
+	client := redis.NewClient(redisOptions)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	err = client.Ping(ctx).Err()
+	latency := time.Since(start)
+	if err != nil {
+		return DatabaseStatus{
+			Reachable: false,
+			Error:     err.Error(),
+		}, nil
+	}
+
+	info, err := client.Info(ctx, "server", "replication").Result()
+	if err != nil {
+		return DatabaseStatus{
+			Reachable: false,
+			Error:     err.Error(),
+		}, nil
+	}
+
+	fields := parseRedisInfo(info)
+
+	role := primaryRole
+	if fields["role"] == "slave" {
+		role = replicaRole
+	}
+
+	return DatabaseStatus{
+		Reachable: true,
+		Latency:   latency,
+		Version:   fields["redis_version"],
+		Role:      role,
+	}, nil
+}
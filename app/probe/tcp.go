@@ -0,0 +1,74 @@
+package probe
+
+import (
+	"fmt"
+	"hermes/app/types"
+	"net"
+	"time"
+)
+
+const defaultTCPTimeout = 5 * time.Second
+
+var _ types.ResourceStatus = TCPStatus{}
+
+type TCPStatus struct {
+	Reachable bool          `json:"exists"`
+	Latency   time.Duration `json:"latency"`
+	Error     string        `json:"error,omitempty"`
+}
+
+func (t TCPStatus) IsResourceStatus() {}
+
+func (t TCPStatus) GetHealth() types.Health {
+	if !t.Reachable {
+		return unreachable(t.Error)
+	}
+
+	return types.Healthy()
+}
+
+// unreachable is the health of a probe that couldn't reach its target
+func unreachable(err string) types.Health {
+	if err == "" {
+		return types.Unhealthy("unreachable")
+	}
+
+	return types.Unhealthy(fmt.Sprintf("unreachable: %s", err))
+}
+
+func (t TCPStatus) Exists() bool {
+	return t.Reachable
+}
+
+func (t TCPStatus) GetStatusString() string {
+	if !t.Reachable {
+		return "unreachable"
+	}
+
+	return "ok"
+}
+
+// GetTCPStatus checks that a connection to address (host:port) can be
+// opened within the configured timeout
+func GetTCPStatus(address string, options *types.TCPProbeOptions) (TCPStatus, error) {
+	timeout := defaultTCPTimeout
+	if options != nil && options.Timeout != 0 {
+		timeout = options.Timeout
+	}
+
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	latency := time.Since(start)
+	if err != nil {
+		return TCPStatus{
+			Reachable: false,
+			Error:     err.Error(),
+		}, nil
+	}
+	defer conn.Close()
+
+	return TCPStatus{
+		Reachable: true,
+		Latency:   latency,
+	}, nil
+}
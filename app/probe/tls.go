@@ -0,0 +1,181 @@
+package probe
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"hermes/app/types"
+	"net"
+	"strings"
+	"time"
+)
+
+const defaultTLSTimeout = 10 * time.Second
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// IsTLSVersion reports whether s is a version accepted as a minimum TLS
+// version, e.g. "1.2"
+func IsTLSVersion(s string) bool {
+	_, found := tlsVersions[s]
+	return found
+}
+
+var _ types.ResourceStatus = TLSStatus{}
+
+type TLSStatus struct {
+	Reachable    bool      `json:"exists"`
+	Protocol     string    `json:"protocol"`
+	Subject      string    `json:"subject"`
+	SANs         []string  `json:"sans"`
+	Issuer       string    `json:"issuer"`
+	NotAfter     time.Time `json:"not_after"`
+	DaysToExpiry int       `json:"days_to_expiry"`
+	ChainValid   bool      `json:"chain_valid"`
+	ChainError   string    `json:"chain_error,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	Failures     []string  `json:"failures"`
+}
+
+func (t TLSStatus) IsResourceStatus() {}
+
+// GetHealth reports a valid certificate that is close to expiring, or a
+// connection below the minimum version, as degraded
+func (t TLSStatus) GetHealth() types.Health {
+	if !t.Reachable {
+		return unreachable(t.Error)
+	}
+
+	if !t.ChainValid {
+		return types.Unhealthy(strings.Join(t.Failures, ", "))
+	}
+
+	if len(t.Failures) > 0 {
+		return types.Degraded(strings.Join(t.Failures, ", "))
+	}
+
+	return types.Healthy()
+}
+
+func (t TLSStatus) Exists() bool {
+	return t.Reachable
+}
+
+func (t TLSStatus) GetStatusString() string {
+	if !t.Reachable {
+		return "unreachable"
+	}
+
+	if len(t.Failures) > 0 {
+		return "failing"
+	}
+
+	return "ok"
+}
+
+// GetTLSStatus dials address (host:port, port 443 when omitted) and inspects
+// the certificate chain it serves. The chain is verified separately from the
+// handshake so that invalid certificates can still be reported on
+func GetTLSStatus(address string, options *types.TLSProbeOptions) (TLSStatus, error) {
+	probeOptions := types.TLSProbeOptions{}
+	if options != nil {
+		probeOptions = *options
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+		address = net.JoinHostPort(address, "443")
+	}
+
+	serverName := probeOptions.ServerName
+	if serverName == "" {
+		serverName = host
+	}
+
+	timeout := probeOptions.Timeout
+	if timeout == 0 {
+		timeout = defaultTLSTimeout
+	}
+
+	dialer := &net.Dialer{
+		Timeout: timeout,
+	}
+
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{
+		ServerName: serverName,
+		// verification happens below so invalid chains can be described
+		InsecureSkipVerify: true,
+		// go refuses anything older than 1.2 by default, which would fail
+		// the handshake rather than report the old protocol
+		MinVersion: tls.VersionTLS10,
+	})
+	if err != nil {
+		return TLSStatus{
+			Reachable: false,
+			Error:     err.Error(),
+			Failures:  []string{"handshake failed"},
+		}, nil
+	}
+	defer conn.Close()
+
+	state := conn.ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		return TLSStatus{}, fmt.Errorf("no certificates presented by %s", address)
+	}
+
+	leaf := state.PeerCertificates[0]
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	status := TLSStatus{
+		Reachable:    true,
+		Protocol:     tls.VersionName(state.Version),
+		Subject:      leaf.Subject.String(),
+		SANs:         leaf.DNSNames,
+		Issuer:       leaf.Issuer.String(),
+		NotAfter:     leaf.NotAfter,
+		DaysToExpiry: int(time.Until(leaf.NotAfter).Hours() / 24),
+		ChainValid:   true,
+		Failures:     []string{},
+	}
+
+	_, err = leaf.Verify(x509.VerifyOptions{
+		DNSName:       serverName,
+		Intermediates: intermediates,
+	})
+	if err != nil {
+		status.ChainValid = false
+		status.ChainError = err.Error()
+		status.Failures = append(status.Failures, "certificate chain is invalid")
+	}
+
+	if status.DaysToExpiry < probeOptions.MinDaysToExpiry {
+		status.Failures = append(status.Failures,
+			fmt.Sprintf("certificate expires in %d days, minimum is %d", status.DaysToExpiry, probeOptions.MinDaysToExpiry),
+		)
+	}
+
+	if probeOptions.MinVersion != "" {
+		minVersion, found := tlsVersions[probeOptions.MinVersion]
+		if !found {
+			return TLSStatus{}, fmt.Errorf("invalid minimum tls version: %s", probeOptions.MinVersion)
+		}
+
+		if state.Version < minVersion {
+			status.Failures = append(status.Failures,
+				fmt.Sprintf("negotiated %s, minimum is TLS %s", status.Protocol, probeOptions.MinVersion),
+			)
+		}
+	}
+
+	return status, nil
+}
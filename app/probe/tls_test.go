@@ -0,0 +1,74 @@
+package probe
+
+import (
+	"crypto/tls"
+	"hermes/app/types"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetTLSStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxVersion   uint16
+		minVersion   string
+		wantProtocol string
+		wantFailures int
+	}{
+		{
+			name:         "modern server",
+			maxVersion:   tls.VersionTLS13,
+			minVersion:   "1.2",
+			wantProtocol: "TLS 1.3",
+		},
+		{
+			name:         "legacy server is reported",
+			maxVersion:   tls.VersionTLS10,
+			wantProtocol: "TLS 1.0",
+		},
+		{
+			name:         "legacy server under the minimum",
+			maxVersion:   tls.VersionTLS11,
+			minVersion:   "1.2",
+			wantProtocol: "TLS 1.1",
+			wantFailures: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewUnstartedServer(http.NotFoundHandler())
+			server.TLS = &tls.Config{MinVersion: tls.VersionTLS10, MaxVersion: test.maxVersion}
+			server.StartTLS()
+			t.Cleanup(server.Close)
+
+			// the test certificate is issued for example.com
+			status, err := GetTLSStatus(strings.TrimPrefix(server.URL, "https://"), &types.TLSProbeOptions{
+				ServerName: "example.com",
+				MinVersion: test.minVersion,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !status.Reachable {
+				t.Fatalf("unreachable: %s", status.Error)
+			}
+
+			if status.Protocol != test.wantProtocol {
+				t.Errorf("got protocol %s, want %s", status.Protocol, test.wantProtocol)
+			}
+
+			// the test certificate isn't signed by a trusted root
+			if status.ChainValid {
+				t.Error("expected the chain not to verify")
+			}
+
+			if len(status.Failures) != test.wantFailures+1 {
+				t.Errorf("got failures %q, want %d besides the chain", status.Failures, test.wantFailures)
+			}
+		})
+	}
+}
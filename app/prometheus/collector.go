@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"hermes/app/common"
 	"hermes/app/types"
-	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -20,14 +19,19 @@ type ProjectStats struct {
 type basicCollector struct {
 	TotalResources       *prometheus.Desc
 	HealthyResources     *prometheus.Desc
+	DegradedResources    *prometheus.Desc
 	FailedFetchResources *prometheus.Desc
 	ResourceStatusString *prometheus.Desc
+	ResourceHealth       *prometheus.Desc
+	DeploymentHealth     *prometheus.Desc
+	DriftedResources     *prometheus.Desc
+	ResourceDrifted      *prometheus.Desc
 
-	projectDefinitions []types.ProjectDefinition
-	clients            common.Clients
+	projects *common.ProjectStore
+	clients  common.Clients
 }
 
-func NewBasicCollector(projectDefinitions []types.ProjectDefinition, clients common.Clients) prometheus.Collector {
+func NewBasicCollector(projects *common.ProjectStore, clients common.Clients) prometheus.Collector {
 	return &basicCollector{
 		TotalResources: prometheus.NewDesc(
 			"resources_total",
@@ -41,6 +45,12 @@ func NewBasicCollector(projectDefinitions []types.ProjectDefinition, clients com
 			[]string{"project", "deployment"},
 			nil,
 		),
+		DegradedResources: prometheus.NewDesc(
+			"resources_degraded",
+			"Number of degraded resources",
+			[]string{"project", "deployment"},
+			nil,
+		),
 		FailedFetchResources: prometheus.NewDesc(
 			"resources_failed_fetch",
 			"Number of resources whose status couldn't be fetched",
@@ -53,99 +63,190 @@ func NewBasicCollector(projectDefinitions []types.ProjectDefinition, clients com
 			[]string{"project", "deployment", "resource", "type", "status"},
 			nil,
 		),
-		projectDefinitions: projectDefinitions,
-		clients:            clients,
+		ResourceHealth: prometheus.NewDesc(
+			"resource_health",
+			"Health state of a resource, 1 for the current state",
+			[]string{"project", "deployment", "resource", "type", "state"},
+			nil,
+		),
+		DeploymentHealth: prometheus.NewDesc(
+			"deployment_health",
+			"Health state of a deployment rolled up from its resources, 1 for the current state",
+			[]string{"project", "deployment", "state"},
+			nil,
+		),
+		DriftedResources: prometheus.NewDesc(
+			"resources_drifted",
+			"Number of resources whose properties differ from those expected",
+			[]string{"project", "deployment"},
+			nil,
+		),
+		ResourceDrifted: prometheus.NewDesc(
+			"resource_drifted",
+			"Whether a resource's properties differ from those expected",
+			[]string{"project", "deployment", "resource", "type"},
+			nil,
+		),
+		projects: projects,
+		clients:  clients,
 	}
 }
 
 func (c *basicCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.TotalResources
 	ch <- c.HealthyResources
+	ch <- c.DegradedResources
+	ch <- c.ResourceHealth
+	ch <- c.DeploymentHealth
+	ch <- c.DriftedResources
+	ch <- c.ResourceDrifted
 }
 
 // https://stackoverflow.com/questions/68887416/grafana-state-timeline-panel-with-values-states-supplied-by-label
 func (c *basicCollector) Collect(ch chan<- prometheus.Metric) {
-	for _, project := range c.projectDefinitions {
+	for _, project := range c.projects.Get() {
 		for _, deployment := range project.Deployments {
-			var wg sync.WaitGroup
-			var mu sync.Mutex
-			totalResources := len(deployment.Resources)
-			healthyResources := 0
-			failedFetchResources := 0
-			for _, resource := range deployment.Resources {
-				wg.Add(1)
-				go func() {
-					defer wg.Done()
-
-					status, err := common.GetResourceStatus(&c.clients, resource)
-
-					if err != nil {
-						mu.Lock()
-						fmt.Println(
-							"error fetching resource status",
-							project.Name,
-							deployment.Name,
-							resource.Name,
-							err,
-						)
-						failedFetchResources += 1
-						mu.Unlock()
-						return
-					}
-
-					healthValue := 0
-					if status.IsHealthy() {
-						healthValue = 1
-					}
-
+			snapshot := common.GetDeploymentSnapshot(&c.clients, deployment)
+
+			stateCounts := map[types.HealthState]int{}
+			driftedResources := 0
+			for _, resourceSnapshot := range snapshot.Resources {
+				resource := resourceSnapshot.Definition
+				state := resourceSnapshot.Health.State
+				stateCounts[state] += 1
+
+				statusString := string(types.HealthUnknown)
+				if state == types.HealthUnknown {
+					fmt.Println(
+						"error fetching resource status",
+						project.Name,
+						deployment.Name,
+						resource.Name,
+						resourceSnapshot.Health.Reason,
+					)
+				} else {
+					statusString = resourceSnapshot.Status.GetStatusString()
+				}
+
+				healthValue := 0
+				if state == types.HealthHealthy {
+					healthValue = 1
+				}
+
+				ch <- prometheus.MustNewConstMetric(
+					c.ResourceStatusString,
+					prometheus.GaugeValue,
+					float64(healthValue),
+					project.Name,
+					deployment.Name,
+					resource.Name,
+					string(resource.Type),
+					statusString,
+				)
+
+				for _, healthState := range healthStates {
 					ch <- prometheus.MustNewConstMetric(
-						c.ResourceStatusString,
+						c.ResourceHealth,
 						prometheus.GaugeValue,
-						float64(healthValue),
+						stateValue(state, healthState),
 						project.Name,
 						deployment.Name,
 						resource.Name,
 						string(resource.Type),
-						status.GetStatusString(),
+						string(healthState),
 					)
-
-					if status.IsHealthy() {
-						mu.Lock()
-						healthyResources += 1
-						mu.Unlock()
-					}
-
-				}()
+				}
+
+				if state == types.HealthUnknown {
+					continue
+				}
+
+				driftValue := 0
+				if resourceSnapshot.Drifted {
+					driftValue = 1
+					driftedResources += 1
+				}
+
+				ch <- prometheus.MustNewConstMetric(
+					c.ResourceDrifted,
+					prometheus.GaugeValue,
+					float64(driftValue),
+					project.Name,
+					deployment.Name,
+					resource.Name,
+					string(resource.Type),
+				)
 			}
 
-			wg.Wait()
-
 			ch <- prometheus.MustNewConstMetric(
 				c.TotalResources,
 				prometheus.GaugeValue,
-				float64(totalResources),
+				float64(len(deployment.Resources)),
 				project.Name,
 				deployment.Name,
 			)
 			ch <- prometheus.MustNewConstMetric(
 				c.HealthyResources,
 				prometheus.GaugeValue,
-				float64(healthyResources),
+				float64(stateCounts[types.HealthHealthy]),
+				project.Name,
+				deployment.Name,
+			)
+
+			ch <- prometheus.MustNewConstMetric(
+				c.DegradedResources,
+				prometheus.GaugeValue,
+				float64(stateCounts[types.HealthDegraded]),
+				project.Name,
+				deployment.Name,
+			)
+
+			ch <- prometheus.MustNewConstMetric(
+				c.DriftedResources,
+				prometheus.GaugeValue,
+				float64(driftedResources),
 				project.Name,
 				deployment.Name,
 			)
 
-			// TODO: do we need a per-resource metric to show which ones failed, or will this be obvious
-			// if there is no entry
 			ch <- prometheus.MustNewConstMetric(
 				c.FailedFetchResources,
 				prometheus.GaugeValue,
-				float64(failedFetchResources),
+				float64(stateCounts[types.HealthUnknown]),
 				project.Name,
 				deployment.Name,
 			)
+
+			for _, healthState := range healthStates {
+				ch <- prometheus.MustNewConstMetric(
+					c.DeploymentHealth,
+					prometheus.GaugeValue,
+					stateValue(snapshot.Health.State, healthState),
+					project.Name,
+					deployment.Name,
+					string(healthState),
+				)
+			}
 		}
 	}
 
 	// log.Printf("Metrics collected - Total: %d, Healthy: %d", stats.TotalResources, stats.HealthyResources)
 }
+
+// healthStates are the values of the state label on health metrics. Every
+// state gets a series, set to 1 for the current one, so a state timeline can
+// be drawn without gaps
+var healthStates = []types.HealthState{
+	types.HealthHealthy,
+	types.HealthDegraded,
+	types.HealthUnhealthy,
+	types.HealthUnknown,
+}
+
+func stateValue(state types.HealthState, series types.HealthState) float64 {
+	if state == series {
+		return 1
+	}
+
+	return 0
+}
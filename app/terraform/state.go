@@ -0,0 +1,229 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"hermes/app/types"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+const (
+	GroupByWorkspace = "workspace"
+	GroupByModule    = "module"
+)
+
+const (
+	defaultWorkspace = "default"
+	rootModule       = "root"
+)
+
+// where a resource type keeps the identifier hermes looks it up by. Several
+// attributes are listed where the name changed between provider versions,
+// and the first non-empty one is used
+type resourceMapping struct {
+	resourceType types.ResourceType
+	attributes   []string
+}
+
+var resourceMappings = map[string]resourceMapping{
+	"aws_ecs_cluster":                          {types.ECSResource, []string{"name"}},
+	"aws_db_instance":                          {types.RDSResource, []string{"identifier"}},
+	"aws_lb":                                   {types.ELBResource, []string{"name"}},
+	"aws_alb":                                  {types.ELBResource, []string{"name"}},
+	"aws_apigatewayv2_api":                     {types.APIGatewayResource, []string{"id"}},
+	"aws_api_gateway_rest_api":                 {types.APIGatewayRestResource, []string{"id"}},
+	"aws_lambda_function":                      {types.LambdaResource, []string{"function_name"}},
+	"cloudflare_pages_project":                 {types.CloudflarePagesResource, []string{"name"}},
+	"cloudflare_worker_script":                 {types.CloudflareWorkersResource, []string{"name"}},
+	"cloudflare_workers_script":                {types.CloudflareWorkersResource, []string{"script_name", "name"}},
+	"cloudflare_zone":                          {types.CloudflareZoneResource, []string{"zone", "name"}},
+	"cloudflare_tunnel":                        {types.CloudflareTunnelResource, []string{"name"}},
+	"cloudflare_zero_trust_tunnel_cloudflared": {types.CloudflareTunnelResource, []string{"name"}},
+	"cloudflare_workers_kv_namespace":          {types.CloudflareKVResource, []string{"title"}},
+	"cloudflare_r2_bucket":                     {types.CloudflareR2Resource, []string{"name"}},
+	"cloudflare_d1_database":                   {types.CloudflareD1Resource, []string{"name"}},
+}
+
+// the subset of the v4 state format hermes reads
+type state struct {
+	Version   int             `json:"version"`
+	Resources []stateResource `json:"resources"`
+}
+
+type stateResource struct {
+	Mode      string `json:"mode"`
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Module    string `json:"module"`
+	Instances []struct {
+		Attributes map[string]any `json:"attributes"`
+	} `json:"instances"`
+}
+
+func readState(path string) (state, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state{}, err
+	}
+
+	var parsed state
+	err = json.Unmarshal(data, &parsed)
+	if err != nil {
+		return state{}, fmt.Errorf("invalid terraform state %s: %w", path, err)
+	}
+
+	if parsed.Version != 4 {
+		return state{}, fmt.Errorf("unsupported terraform state version %d: %s", parsed.Version, path)
+	}
+
+	return parsed, nil
+}
+
+// workspaceName infers the workspace a local state file belongs to. The
+// default workspace is kept in terraform.tfstate, and others in
+// terraform.tfstate.d/<workspace>/terraform.tfstate
+func workspaceName(path string) string {
+	dir := filepath.Dir(path)
+	if filepath.Base(filepath.Dir(dir)) == "terraform.tfstate.d" {
+		return filepath.Base(dir)
+	}
+
+	return defaultWorkspace
+}
+
+// matches one module step of an address, such as module.api, module.api[0]
+// or module.api["eu-west"]
+var moduleStep = regexp.MustCompile(`module\.([^.\[]+)(?:\[(\d+|"(?:[^"\\]|\\.)*")\])?`)
+
+// moduleName turns a module address such as module.api.module.db into api.db.
+// Instances of a module using count or for_each keep their key without its
+// quotes, so module.api["eu-west"].module.db becomes api[eu-west].db
+func moduleName(address string) string {
+	if address == "" {
+		return rootModule
+	}
+
+	parts := []string{}
+	for _, match := range moduleStep.FindAllStringSubmatch(address, -1) {
+		part := match[1]
+		if match[2] != "" {
+			key, err := strconv.Unquote(match[2])
+			if err != nil {
+				key = match[2]
+			}
+
+			part += "[" + key + "]"
+		}
+
+		parts = append(parts, part)
+	}
+
+	return strings.Join(parts, ".")
+}
+
+func getIdentifier(attributes map[string]any, names []string) string {
+	for _, name := range names {
+		value, ok := attributes[name].(string)
+		if ok && value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// newResourceDefinition maps one instance of a state resource, reporting
+// false for instances hermes can't monitor
+func newResourceDefinition(mapping resourceMapping, attributes map[string]any) (types.ResourceDefinition, bool) {
+	identifier := getIdentifier(attributes, mapping.attributes)
+	if identifier == "" {
+		return types.ResourceDefinition{}, false
+	}
+
+	// gateway load balancers share the aws_lb type but aren't supported
+	if mapping.resourceType == types.ELBResource && attributes["load_balancer_type"] == "gateway" {
+		return types.ResourceDefinition{}, false
+	}
+
+	resource := types.ResourceDefinition{
+		Name:       identifier,
+		Identifier: identifier,
+		Type:       mapping.resourceType,
+	}
+
+	// the state doesn't record the provider's region, but the arn does
+	if resourceARN, ok := attributes["arn"].(string); ok {
+		parsed, err := arn.Parse(resourceARN)
+		if err == nil && parsed.Region != "" {
+			resource.AWS = &types.AWSTarget{Region: parsed.Region}
+		}
+	}
+
+	return resource, true
+}
+
+// Import reads the state files matched by the source's globs and returns
+// the resources hermes can monitor, grouped into a deployment per
+// workspace or module
+func Import(source *types.TerraformSource) ([]types.DeploymentDefinition, error) {
+	groupBy := GroupByWorkspace
+	if source.GroupBy != "" {
+		groupBy = source.GroupBy
+	}
+
+	paths := []string{}
+	for _, pattern := range source.States {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no terraform state found matching %s", pattern)
+		}
+
+		paths = append(paths, matches...)
+	}
+
+	deployments := []types.DeploymentDefinition{}
+	for _, path := range paths {
+		parsed, err := readState(path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, stateResource := range parsed.Resources {
+			mapping, found := resourceMappings[stateResource.Type]
+			if stateResource.Mode != "managed" || !found {
+				continue
+			}
+
+			deploymentName := workspaceName(path)
+			if groupBy == GroupByModule {
+				deploymentName = moduleName(stateResource.Module)
+			}
+
+			deploymentIdx := slices.IndexFunc(deployments, func(d types.DeploymentDefinition) bool { return d.Name == deploymentName })
+			if deploymentIdx == -1 {
+				deployments = append(deployments, types.DeploymentDefinition{Name: deploymentName})
+				deploymentIdx = len(deployments) - 1
+			}
+
+			for _, instance := range stateResource.Instances {
+				resource, ok := newResourceDefinition(mapping, instance.Attributes)
+				if ok {
+					deployments[deploymentIdx].AddResource(resource)
+				}
+			}
+		}
+	}
+
+	return deployments, nil
+}
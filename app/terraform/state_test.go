@@ -0,0 +1,132 @@
+package terraform
+
+import (
+	"hermes/app/types"
+	"reflect"
+	"testing"
+)
+
+var testStates = []string{
+	"testdata/terraform.tfstate",
+	"testdata/terraform.tfstate.d/*/terraform.tfstate",
+}
+
+// summarize lists each deployment's resources as type, identifier and region
+func summarize(deployments []types.DeploymentDefinition) map[string][]string {
+	summary := map[string][]string{}
+	for _, deployment := range deployments {
+		resources := []string{}
+		for _, resource := range deployment.Resources {
+			region := ""
+			if resource.AWS != nil {
+				region = resource.AWS.Region
+			}
+
+			resources = append(resources, string(resource.Type)+" "+resource.Identifier+" "+region)
+		}
+
+		summary[deployment.Name] = resources
+	}
+
+	return summary
+}
+
+func TestImport(t *testing.T) {
+	tests := []struct {
+		name    string
+		groupBy string
+		want    map[string][]string
+	}{
+		{
+			name: "grouped by workspace",
+			want: map[string][]string{
+				"default": {
+					"aws-ecs shop eu-west-1",
+					"aws-elb shop-alb eu-west-1",
+					"aws-lambda shop-api us-east-1",
+					"aws-rds shop-db us-east-1",
+					"cloudflare-r2 shop-assets ",
+				},
+				"staging": {
+					"aws-ecs shop-staging eu-west-1",
+					"aws-lambda worker-0 eu-west-1",
+					"aws-lambda worker-1 eu-west-1",
+				},
+			},
+		},
+		{
+			name:    "grouped by module",
+			groupBy: GroupByModule,
+			want: map[string][]string{
+				"root": {
+					"aws-ecs shop eu-west-1",
+					"aws-elb shop-alb eu-west-1",
+					"aws-ecs shop-staging eu-west-1",
+				},
+				"api":           {"aws-lambda shop-api us-east-1"},
+				"api.db":        {"aws-rds shop-db us-east-1"},
+				"edge[eu-west]": {"cloudflare-r2 shop-assets "},
+				"workers": {
+					"aws-lambda worker-0 eu-west-1",
+					"aws-lambda worker-1 eu-west-1",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			deployments, err := Import(&types.TerraformSource{States: testStates, GroupBy: test.groupBy})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := summarize(deployments)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		states []string
+	}{
+		{"no matching state", []string{"testdata/missing/*.tfstate"}},
+		{"unsupported version", []string{"testdata/legacy.tfstate"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Import(&types.TerraformSource{States: test.states})
+			if err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestModuleName(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+	}{
+		{"", "root"},
+		{"module.api", "api"},
+		{"module.api.module.db", "api.db"},
+		{"module.api[0]", "api[0]"},
+		{`module.edge["eu-west"]`, "edge[eu-west]"},
+		{`module.edge["eu.west"].module.cache[2]`, "edge[eu.west].cache[2]"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.address, func(t *testing.T) {
+			got := moduleName(test.address)
+			if got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -1,49 +1,617 @@
 package types
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 type ResourceType string
 
 const (
-	ECSResource             ResourceType = "aws-ecs"
-	RDSResource             ResourceType = "aws-rds"
-	ELBResource             ResourceType = "aws-elb"
-	APIGatewayResource      ResourceType = "aws-apigw"
-	CloudflarePagesResource ResourceType = "cloudflare-pages"
+	ECSResource                    ResourceType = "aws-ecs"
+	RDSResource                    ResourceType = "aws-rds"
+	ELBResource                    ResourceType = "aws-elb"
+	APIGatewayResource             ResourceType = "aws-apigw"
+	APIGatewayRestResource         ResourceType = "aws-apigw-rest"
+	LambdaResource                 ResourceType = "aws-lambda"
+	CloudFormationResource         ResourceType = "aws-cloudformation"
+	CloudflarePagesResource        ResourceType = "cloudflare-pages"
+	CloudflareWorkersResource      ResourceType = "cloudflare-workers"
+	CloudflareZoneResource         ResourceType = "cloudflare-zone"
+	CloudflareDNSRecordResource    ResourceType = "cloudflare-dns-record"
+	CloudflareTunnelResource       ResourceType = "cloudflare-tunnel"
+	CloudflareLoadBalancerResource ResourceType = "cloudflare-lb"
+	CloudflareR2Resource           ResourceType = "cloudflare-r2"
+	CloudflareKVResource           ResourceType = "cloudflare-kv"
+	CloudflareD1Resource           ResourceType = "cloudflare-d1"
+	HTTPResource                   ResourceType = "http"
+	TLSResource                    ResourceType = "tls"
+	TCPResource                    ResourceType = "tcp"
+	DNSResource                    ResourceType = "dns"
+	K8sDeploymentResource          ResourceType = "k8s-deployment"
+	K8sStatefulSetResource         ResourceType = "k8s-statefulset"
+	K8sDaemonSetResource           ResourceType = "k8s-daemonset"
+	K8sPodResource                 ResourceType = "k8s-pod"
+	DockerContainerResource        ResourceType = "docker-container"
+	PostgresResource               ResourceType = "postgres"
+	MySQLResource                  ResourceType = "mysql"
+	RedisResource                  ResourceType = "redis"
+	GitHubWorkflowResource         ResourceType = "github-workflow"
+	GitHubReleaseResource          ResourceType = "github-release"
+	VercelProjectResource          ResourceType = "vercel-project"
+	NetlifySiteResource            ResourceType = "netlify-site"
+	FlyAppResource                 ResourceType = "fly-app"
+	GCPCloudRunResource            ResourceType = "gcp-cloudrun"
+	GCPCloudSQLResource            ResourceType = "gcp-cloudsql"
+	AzureWebAppResource            ResourceType = "azure-webapp"
+	AzureSQLResource               ResourceType = "azure-sql"
 )
 
+func (r ResourceType) IsAWS() bool {
+	return strings.HasPrefix(string(r), "aws")
+}
+
+func (r ResourceType) IsCloudflare() bool {
+	return strings.HasPrefix(string(r), "cloudflare")
+}
+
+func (r ResourceType) IsKubernetes() bool {
+	return strings.HasPrefix(string(r), "k8s")
+}
+
+func (r ResourceType) IsDocker() bool {
+	return strings.HasPrefix(string(r), "docker")
+}
+
+func (r ResourceType) IsGitHub() bool {
+	return strings.HasPrefix(string(r), "github")
+}
+
+func (r ResourceType) IsGCP() bool {
+	return strings.HasPrefix(string(r), "gcp")
+}
+
+func (r ResourceType) IsAzure() bool {
+	return strings.HasPrefix(string(r), "azure")
+}
+
 func IsResourceType(s string) bool {
 	return s == string(ECSResource) ||
 		s == string(RDSResource) ||
 		s == string(ELBResource) ||
 		s == string(APIGatewayResource) ||
-		s == string(CloudflarePagesResource)
+		s == string(APIGatewayRestResource) ||
+		s == string(LambdaResource) ||
+		s == string(CloudFormationResource) ||
+		s == string(CloudflarePagesResource) ||
+		s == string(CloudflareWorkersResource) ||
+		s == string(CloudflareZoneResource) ||
+		s == string(CloudflareDNSRecordResource) ||
+		s == string(CloudflareTunnelResource) ||
+		s == string(CloudflareLoadBalancerResource) ||
+		s == string(CloudflareR2Resource) ||
+		s == string(CloudflareKVResource) ||
+		s == string(CloudflareD1Resource) ||
+		s == string(HTTPResource) ||
+		s == string(TLSResource) ||
+		s == string(TCPResource) ||
+		s == string(DNSResource) ||
+		s == string(K8sDeploymentResource) ||
+		s == string(K8sStatefulSetResource) ||
+		s == string(K8sDaemonSetResource) ||
+		s == string(K8sPodResource) ||
+		s == string(DockerContainerResource) ||
+		s == string(PostgresResource) ||
+		s == string(MySQLResource) ||
+		s == string(RedisResource) ||
+		s == string(GitHubWorkflowResource) ||
+		s == string(GitHubReleaseResource) ||
+		s == string(VercelProjectResource) ||
+		s == string(NetlifySiteResource) ||
+		s == string(FlyAppResource) ||
+		s == string(GCPCloudRunResource) ||
+		s == string(GCPCloudSQLResource) ||
+		s == string(AzureWebAppResource) ||
+		s == string(AzureSQLResource)
+}
+
+// RDSOptions holds optional health thresholds for aws-rds resources, a zero
+// value disables the corresponding check
+type RDSOptions struct {
+	MinFreeStorageGiB float64       `json:"min_free_storage_gib" yaml:"min_free_storage_gib"`
+	MaxBackupAge      time.Duration `json:"max_backup_age" yaml:"max_backup_age"`
+}
+
+// WorkersOptions lists the zones whose routes are checked for a
+// cloudflare-workers script
+type WorkersOptions struct {
+	Zones []string `json:"zones" yaml:"zones"`
+}
+
+// ZoneOptions declares the nameservers a cloudflare-zone is expected to be
+// assigned, left empty they are reported but not checked
+type ZoneOptions struct {
+	NameServers []string `json:"name_servers" yaml:"name_servers"`
+}
+
+// DNSRecordOptions locates a cloudflare-dns-record and declares what it is
+// expected to contain. Zone is required, the rest are only checked when set
+type DNSRecordOptions struct {
+	Zone    string `json:"zone" yaml:"zone"`
+	Type    string `json:"type,omitempty" yaml:"type"`
+	Content string `json:"content,omitempty" yaml:"content"`
+	Proxied *bool  `json:"proxied,omitempty" yaml:"proxied"`
+}
+
+// LoadBalancerOptions names the zone a cloudflare-lb belongs to
+type LoadBalancerOptions struct {
+	Zone string `json:"zone" yaml:"zone"`
+}
+
+// HTTPProbeOptions configures the request made by an http resource and the
+// checks applied to its response. By default any 2xx status passes and
+// redirects are followed
+type HTTPProbeOptions struct {
+	Method          string            `json:"method,omitempty" yaml:"method"`
+	Headers         map[string]string `json:"headers,omitempty" yaml:"headers"`
+	Timeout         time.Duration     `json:"timeout,omitempty" yaml:"timeout"`
+	FollowRedirects *bool             `json:"follow_redirects,omitempty" yaml:"follow_redirects"`
+	ExpectedStatus  []int             `json:"expected_status,omitempty" yaml:"expected_status"`
+	BodyContains    string            `json:"body_contains,omitempty" yaml:"body_contains"`
+	BodyRegex       string            `json:"body_regex,omitempty" yaml:"body_regex"`
+	// expected values keyed by gjson path, e.g. "checks.db.status": "up"
+	JSON       map[string]any `json:"json,omitempty" yaml:"json"`
+	MaxLatency time.Duration  `json:"max_latency,omitempty" yaml:"max_latency"`
+}
+
+// TLSProbeOptions configures a tls resource. ServerName defaults to the
+// host being dialed, and MinVersion is written like "1.2"
+type TLSProbeOptions struct {
+	ServerName      string        `json:"server_name,omitempty" yaml:"server_name"`
+	Timeout         time.Duration `json:"timeout,omitempty" yaml:"timeout"`
+	MinDaysToExpiry int           `json:"min_days_to_expiry,omitempty" yaml:"min_days_to_expiry"`
+	MinVersion      string        `json:"min_version,omitempty" yaml:"min_version"`
+}
+
+// TCPProbeOptions configures a tcp resource
+type TCPProbeOptions struct {
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout"`
+}
+
+// DNSProbeOptions configures a dns resource. Resolver is host or host:port,
+// defaulting to the system resolver. Expected answers only need to be
+// present, other answers alongside them are allowed
+type DNSProbeOptions struct {
+	Resolver      string        `json:"resolver,omitempty" yaml:"resolver"`
+	Timeout       time.Duration `json:"timeout,omitempty" yaml:"timeout"`
+	ExpectedA     []string      `json:"expected_a,omitempty" yaml:"expected_a"`
+	ExpectedAAAA  []string      `json:"expected_aaaa,omitempty" yaml:"expected_aaaa"`
+	ExpectedCNAME string        `json:"expected_cname,omitempty" yaml:"expected_cname"`
+}
+
+// DatabaseProbeOptions configures the postgres, mysql and redis probes. The
+// password is read from password_env or password_file rather than being
+// stored in the config. Database is ignored by the redis probe
+type DatabaseProbeOptions struct {
+	Username     string        `json:"username,omitempty" yaml:"username"`
+	UsernameEnv  string        `json:"username_env,omitempty" yaml:"username_env"`
+	PasswordEnv  string        `json:"password_env,omitempty" yaml:"password_env"`
+	PasswordFile string        `json:"password_file,omitempty" yaml:"password_file"`
+	Database     string        `json:"database,omitempty" yaml:"database"`
+	TLS          bool          `json:"tls,omitempty" yaml:"tls"`
+	Timeout      time.Duration `json:"timeout,omitempty" yaml:"timeout"`
+}
+
+// GitHubWorkflowOptions narrows a github-workflow resource to the runs on
+// one branch
+type GitHubWorkflowOptions struct {
+	// defaults to the repository's default branch
+	Branch string `json:"branch,omitempty" yaml:"branch"`
+}
+
+// GitHubReleaseOptions configures the staleness check for a github-release
+// resource
+type GitHubReleaseOptions struct {
+	// releases older than this are reported as stale, unset to disable
+	MaxAge time.Duration `json:"max_age,omitempty" yaml:"max_age"`
+}
+
+// KubernetesOptions selects the cluster a k8s resource is read from. With
+// neither set, the in-cluster config is used when available, then the
+// default kubeconfig
+type KubernetesOptions struct {
+	Kubeconfig string `json:"kubeconfig,omitempty" yaml:"kubeconfig"`
+	Context    string `json:"context,omitempty" yaml:"context"`
+}
+
+// DockerOptions selects the engine a docker resource is read from. An
+// empty host falls back to DOCKER_HOST, then the local socket
+type DockerOptions struct {
+	Host string `json:"host,omitempty" yaml:"host"`
+}
+
+// CloudflareAccountDefinition names a set of cloudflare credentials that
+// resources can reference. Secrets aren't stored in the config, only the
+// names of the environment variables holding them
+type CloudflareAccountDefinition struct {
+	Name        string `json:"name" yaml:"name"`
+	AccountID   string `json:"account_id" yaml:"account_id"`
+	APITokenEnv string `json:"api_token_env,omitempty" yaml:"api_token_env"`
+	APIKeyEnv   string `json:"api_key_env,omitempty" yaml:"api_key_env"`
+	EmailEnv    string `json:"email_env,omitempty" yaml:"email_env"`
+}
+
+// GitHubDefinition configures access to the github api. The base url
+// defaults to api.github.com, and the token is read from the named
+// environment variable
+type GitHubDefinition struct {
+	BaseURL  string `json:"base_url,omitempty" yaml:"base_url"`
+	TokenEnv string `json:"token_env,omitempty" yaml:"token_env"`
+}
+
+// VercelDefinition configures access to the vercel api. The token is read
+// from the named environment variable, VERCEL_TOKEN by default
+type VercelDefinition struct {
+	BaseURL  string `json:"base_url,omitempty" yaml:"base_url"`
+	TokenEnv string `json:"token_env,omitempty" yaml:"token_env"`
+	TeamID   string `json:"team_id,omitempty" yaml:"team_id"`
+}
+
+// NetlifyDefinition configures access to the netlify api. The token is read
+// from the named environment variable, NETLIFY_AUTH_TOKEN by default
+type NetlifyDefinition struct {
+	BaseURL  string `json:"base_url,omitempty" yaml:"base_url"`
+	TokenEnv string `json:"token_env,omitempty" yaml:"token_env"`
+}
+
+// FlyDefinition configures access to the fly.io api. The token is read from
+// the named environment variable, FLY_API_TOKEN by default
+type FlyDefinition struct {
+	BaseURL  string `json:"base_url,omitempty" yaml:"base_url"`
+	TokenEnv string `json:"token_env,omitempty" yaml:"token_env"`
+}
+
+// GCPDefinition configures access to google cloud. Credentials are read from
+// a service account json file, falling back to the application default
+// credentials. The project and region are defaults for resources whose
+// identifiers don't include them. The run and sql endpoints override the
+// Cloud Run and Cloud SQL Admin api base urls. Requests are only made
+// without credentials when insecure_no_auth is set, or when both endpoints
+// point at a local fake
+type GCPDefinition struct {
+	CredentialsFile string `json:"credentials_file,omitempty" yaml:"credentials_file"`
+	Project         string `json:"project,omitempty" yaml:"project"`
+	Region          string `json:"region,omitempty" yaml:"region"`
+	RunEndpoint     string `json:"run_endpoint,omitempty" yaml:"run_endpoint"`
+	SQLEndpoint     string `json:"sql_endpoint,omitempty" yaml:"sql_endpoint"`
+	InsecureNoAuth  bool   `json:"insecure_no_auth,omitempty" yaml:"insecure_no_auth"`
+}
+
+// AzureDefinition configures access to azure resource manager. The service
+// principal is read from AZURE_TENANT_ID, AZURE_CLIENT_ID and
+// AZURE_CLIENT_SECRET, and the subscription falls back to
+// AZURE_SUBSCRIPTION_ID. Endpoint and login_endpoint override the api and
+// token base urls. Requests are only made without a service principal when
+// insecure_no_auth is set, or when the endpoint points at a local fake
+type AzureDefinition struct {
+	SubscriptionID string `json:"subscription_id,omitempty" yaml:"subscription_id"`
+	Endpoint       string `json:"endpoint,omitempty" yaml:"endpoint"`
+	LoginEndpoint  string `json:"login_endpoint,omitempty" yaml:"login_endpoint"`
+	InsecureNoAuth bool   `json:"insecure_no_auth,omitempty" yaml:"insecure_no_auth"`
+}
+
+// AWSTarget selects the region and credentials used to reach aws
+// resources. Unset fields fall back to the enclosing deployment and project,
+// then to the default aws config
+type AWSTarget struct {
+	Region     string `json:"region,omitempty" yaml:"region"`
+	Profile    string `json:"profile,omitempty" yaml:"profile"`
+	RoleARN    string `json:"role_arn,omitempty" yaml:"role_arn"`
+	ExternalID string `json:"external_id,omitempty" yaml:"external_id"`
+}
+
+// Inherit fills any fields left unset on t from parent. The external id
+// belongs to the role it was given with, so it is only inherited along with
+// the role arn
+func (t *AWSTarget) Inherit(parent *AWSTarget) *AWSTarget {
+	if parent == nil {
+		return t
+	}
+
+	if t == nil {
+		inherited := *parent
+		return &inherited
+	}
+
+	inherited := *t
+
+	if inherited.Region == "" {
+		inherited.Region = parent.Region
+	}
+
+	if inherited.Profile == "" {
+		inherited.Profile = parent.Profile
+	}
+
+	if inherited.RoleARN == "" {
+		inherited.RoleARN = parent.RoleARN
+		inherited.ExternalID = parent.ExternalID
+	}
+
+	return &inherited
+}
+
+// HealthRules add to how a resource's health is judged. Each rule is a cel
+// expression over the top level fields of the resource's status json, e.g.
+// tasks_running < 2 || status != "ACTIVE", type checked against the fields'
+// types when loaded. Times and durations are cel timestamps and durations.
+// Fields that are omitted from the json when empty still hold their zero
+// value, and optional values are null when unset, e.g. free_storage_gib !=
+// null. The resource type's own check still applies unless the rules replace
+// it
+type HealthRules struct {
+	// the resource is unhealthy when this evaluates to true
+	Unhealthy string `json:"unhealthy,omitempty" yaml:"unhealthy"`
+	// a healthy resource is degraded when this evaluates to true
+	Degraded string `json:"degraded,omitempty" yaml:"degraded"`
+	// judge the resource by the rules alone, ignoring its own check
+	ReplaceBuiltin *bool `json:"replace_builtin,omitempty" yaml:"replace_builtin"`
+}
+
+// Inherit fills any rules left unset on r from parent
+func (r *HealthRules) Inherit(parent *HealthRules) *HealthRules {
+	if parent == nil {
+		return r
+	}
+
+	if r == nil {
+		inherited := *parent
+		return &inherited
+	}
+
+	inherited := *r
+
+	if inherited.Unhealthy == "" {
+		inherited.Unhealthy = parent.Unhealthy
+	}
+
+	if inherited.Degraded == "" {
+		inherited.Degraded = parent.Degraded
+	}
+
+	if inherited.ReplaceBuiltin == nil {
+		inherited.ReplaceBuiltin = parent.ReplaceBuiltin
+	}
+
+	return &inherited
 }
 
 type ResourceDefinition struct {
 	Name       string       `json:"name"`
 	Identifier string       `json:"identifier"`
 	Type       ResourceType `json:"type"`
+
+	// the name of the cloudflare account a cloudflare resource belongs
+	// to, empty for the default account
+	CloudflareAccount string `json:"cloudflare_account,omitempty" yaml:"cloudflare_account"`
+	// where an aws resource lives, inherited from the deployment and
+	// project when loaded
+	AWS *AWSTarget `json:"aws,omitempty" yaml:"aws"`
+
+	RDS          *RDSOptions            `json:"rds,omitempty" yaml:"rds"`
+	Workers      *WorkersOptions        `json:"workers,omitempty" yaml:"workers"`
+	Zone         *ZoneOptions           `json:"zone,omitempty" yaml:"zone"`
+	DNSRecord    *DNSRecordOptions      `json:"dns_record,omitempty" yaml:"dns_record"`
+	LoadBalancer *LoadBalancerOptions   `json:"load_balancer,omitempty" yaml:"load_balancer"`
+	HTTP         *HTTPProbeOptions      `json:"http,omitempty" yaml:"http"`
+	TLS          *TLSProbeOptions       `json:"tls,omitempty" yaml:"tls"`
+	TCP          *TCPProbeOptions       `json:"tcp,omitempty" yaml:"tcp"`
+	DNS          *DNSProbeOptions       `json:"dns,omitempty" yaml:"dns"`
+	Database     *DatabaseProbeOptions  `json:"database,omitempty" yaml:"database"`
+	Kubernetes   *KubernetesOptions     `json:"kubernetes,omitempty" yaml:"kubernetes"`
+	Docker       *DockerOptions         `json:"docker,omitempty" yaml:"docker"`
+	Workflow     *GitHubWorkflowOptions `json:"workflow,omitempty" yaml:"workflow"`
+	Release      *GitHubReleaseOptions  `json:"release,omitempty" yaml:"release"`
+
+	// properties the resource's status is expected to have, keyed by a
+	// dotted path into the status json. Path segments applied to a list
+	// match the element with that name, e.g. services.api.desired_count
+	Expect map[string]any `json:"expect,omitempty" yaml:"expect"`
+	// inherited per rule from the health_rules for the resource's type
+	// when loaded
+	Health *HealthRules `json:"health,omitempty" yaml:"health"`
 }
 
 type DeploymentDefinition struct {
 	Name      string               `json:"name"`
+	AWS       *AWSTarget           `json:"aws,omitempty" yaml:"aws"`
 	Resources []ResourceDefinition `json:"resources"`
 }
 
+// AddResource appends a generated resource unless one with the same type
+// and identifier is already declared. A resource whose name is already
+// taken is suffixed with its type
+func (d *DeploymentDefinition) AddResource(resource ResourceDefinition) {
+	for _, existing := range d.Resources {
+		if existing.Type == resource.Type && existing.Identifier == resource.Identifier {
+			return
+		}
+	}
+
+	for _, existing := range d.Resources {
+		if existing.Name == resource.Name {
+			resource.Name = fmt.Sprintf("%s-%s", resource.Name, resource.Type)
+			break
+		}
+	}
+
+	d.Resources = append(d.Resources, resource)
+}
+
+// TerraformSource imports a project's resources from local v4 state files.
+// States are globs, and deployments are grouped by workspace (the default)
+// or module
+type TerraformSource struct {
+	States  []string `json:"states" yaml:"states"`
+	GroupBy string   `json:"group_by,omitempty" yaml:"group_by"`
+}
+
+// CloudFormationSource imports a deployment per stack, holding the stack
+// itself and its supported resources. Stacks are read with the project's aws
+// settings, and AWS_ENDPOINT_URL_CLOUDFORMATION can point them at a fake
+type CloudFormationSource struct {
+	Stacks []string `json:"stacks" yaml:"stacks"`
+}
+
 type ProjectDefinition struct {
 	Name        string                 `json:"name"`
+	AWS         *AWSTarget             `json:"aws,omitempty" yaml:"aws"`
 	Deployments []DeploymentDefinition `json:"deployments"`
+
+	// where resources beyond the declared deployments come from, empty,
+	// terraform or cloudformation-stack
+	Source         string                `json:"source,omitempty" yaml:"source"`
+	Terraform      *TerraformSource      `json:"terraform,omitempty" yaml:"terraform"`
+	CloudFormation *CloudFormationSource `json:"cloudformation,omitempty" yaml:"cloudformation"`
+}
+
+// DiscoveryRule adds every resource matching it to a deployment, creating
+// the project and deployment if they aren't declared. Aws resources are
+// matched on all of the given tags, cloudflare resources on a glob of their
+// name
+type DiscoveryRule struct {
+	Project           string            `json:"project" yaml:"project"`
+	Deployment        string            `json:"deployment" yaml:"deployment"`
+	Types             []ResourceType    `json:"types" yaml:"types"`
+	Tags              map[string]string `json:"tags,omitempty" yaml:"tags"`
+	Name              string            `json:"name,omitempty" yaml:"name"`
+	CloudflareAccount string            `json:"cloudflare_account,omitempty" yaml:"cloudflare_account"`
+	// inherited from the matching project and deployment when loaded
+	AWS *AWSTarget `json:"aws,omitempty" yaml:"aws"`
+}
+
+type DiscoveryDefinition struct {
+	// how often rules are re-evaluated, five minutes by default
+	Interval time.Duration   `json:"interval,omitempty" yaml:"interval"`
+	Rules    []DiscoveryRule `json:"rules" yaml:"rules"`
+}
+
+// Config is the top level of projects.yaml. For backwards compatibility the
+// file may also be a bare list of projects
+type Config struct {
+	CloudflareAccounts []CloudflareAccountDefinition `json:"cloudflare_accounts" yaml:"cloudflare_accounts"`
+	GitHub             *GitHubDefinition             `json:"github,omitempty" yaml:"github"`
+	Vercel             *VercelDefinition             `json:"vercel,omitempty" yaml:"vercel"`
+	Netlify            *NetlifyDefinition            `json:"netlify,omitempty" yaml:"netlify"`
+	Fly                *FlyDefinition                `json:"fly,omitempty" yaml:"fly"`
+	GCP                *GCPDefinition                `json:"gcp,omitempty" yaml:"gcp"`
+	Azure              *AzureDefinition              `json:"azure,omitempty" yaml:"azure"`
+	Discovery          *DiscoveryDefinition          `json:"discovery,omitempty" yaml:"discovery"`
+	HealthRules        map[ResourceType]HealthRules  `json:"health_rules,omitempty" yaml:"health_rules"`
+	Projects           []ProjectDefinition           `json:"projects" yaml:"projects"`
+}
+
+type HealthState string
+
+const (
+	HealthHealthy   HealthState = "healthy"
+	HealthDegraded  HealthState = "degraded"
+	HealthUnhealthy HealthState = "unhealthy"
+	// the resource's status couldn't be fetched
+	HealthUnknown HealthState = "unknown"
+)
+
+// Health is a resource's health state, with a reason for any state other
+// than healthy
+type Health struct {
+	State  HealthState `json:"state"`
+	Reason string      `json:"reason,omitempty"`
+}
+
+func Healthy() Health {
+	return Health{State: HealthHealthy}
+}
+
+func Degraded(reason string) Health {
+	return Health{State: HealthDegraded, Reason: reason}
+}
+
+func Unhealthy(reason string) Health {
+	return Health{State: HealthUnhealthy, Reason: reason}
+}
+
+func Unknown(reason string) Health {
+	return Health{State: HealthUnknown, Reason: reason}
+}
+
+// RollupHealth combines the health of a deployment's resources. Any
+// unhealthy resource makes the deployment unhealthy, and it is only unknown
+// when none of its resources could be fetched. Otherwise it is degraded
+// while any resource is degraded or unknown
+func RollupHealth(healths []Health) Health {
+	counts := map[HealthState]int{}
+	for _, health := range healths {
+		counts[health.State] += 1
+	}
+
+	switch {
+	case len(healths) == 0:
+		return Unknown("no resources")
+	case counts[HealthUnhealthy] > 0:
+		return Unhealthy(fmt.Sprintf("%d of %d resources unhealthy", counts[HealthUnhealthy], len(healths)))
+	case counts[HealthUnknown] == len(healths):
+		return Unknown(fmt.Sprintf("%d of %d resources unknown", counts[HealthUnknown], len(healths)))
+	case counts[HealthHealthy] == len(healths):
+		return Healthy()
+	}
+
+	reasons := []string{}
+	for _, state := range []HealthState{HealthUnknown, HealthDegraded} {
+		if counts[state] > 0 {
+			reasons = append(reasons, fmt.Sprintf("%d %s", counts[state], state))
+		}
+	}
+
+	return Degraded(fmt.Sprintf("%s of %d resources", strings.Join(reasons, ", "), len(healths)))
 }
 
 type ResourceStatus interface {
 	IsResourceStatus()
-	IsHealthy() bool
+	GetHealth() Health
 	Exists() bool
 	GetStatusString() string
 }
 
+// ResourceHistory is implemented by resource types that can report a record
+// of past deployments in addition to their current status
+type ResourceHistory interface {
+	IsResourceHistory()
+}
+
+// Drift is an expected property whose actual value differs. Actual is nil
+// when the property is missing from the status
+type Drift struct {
+	Path     string `json:"path"`
+	Expected any    `json:"expected"`
+	Actual   any    `json:"actual"`
+}
+
+// ResourceSnapshot is a resource's evaluated status. Status and Exists are
+// nil, and health unknown, when the status couldn't be fetched. DriftError
+// is set when the status was fetched but couldn't be compared
 type ResourceSnapshot struct {
 	Definition ResourceDefinition `json:"definition"`
 	Status     ResourceStatus     `json:"status"`
-	Healthy    bool               `json:"healthy"`
-	Exists     bool               `json:"exists"`
+	Health     Health             `json:"health"`
+	Exists     *bool              `json:"exists,omitempty"`
+	Drifted    bool               `json:"drifted"`
+	Drift      []Drift            `json:"drift"`
+	DriftError string             `json:"drift_error,omitempty"`
+}
+
+type DeploymentSnapshot struct {
+	Name      string             `json:"name"`
+	Health    Health             `json:"health"`
+	Resources []ResourceSnapshot `json:"resources"`
 }
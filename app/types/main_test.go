@@ -0,0 +1,36 @@
+package types
+
+import "testing"
+
+func TestRollupHealth(t *testing.T) {
+	healthy := Healthy()
+	degraded := Degraded("degraded")
+	unhealthy := Unhealthy("unhealthy")
+	unknown := Unknown("unknown")
+
+	tests := []struct {
+		name    string
+		healths []Health
+		want    HealthState
+	}{
+		{"no resources", []Health{}, HealthUnknown},
+		{"all healthy", []Health{healthy, healthy}, HealthHealthy},
+		{"one degraded", []Health{healthy, degraded}, HealthDegraded},
+		{"one unknown", []Health{healthy, unknown}, HealthDegraded},
+		{"one unhealthy", []Health{healthy, healthy, unhealthy}, HealthUnhealthy},
+		{"mostly unhealthy", []Health{healthy, unhealthy, unhealthy, unhealthy, unhealthy, unhealthy}, HealthUnhealthy},
+		{"unhealthy and unknown", []Health{unhealthy, unknown}, HealthUnhealthy},
+		{"unhealthy and degraded", []Health{degraded, unhealthy}, HealthUnhealthy},
+		{"all unknown", []Health{unknown, unknown}, HealthUnknown},
+		{"degraded and unknown", []Health{degraded, unknown}, HealthDegraded},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := RollupHealth(test.healths)
+			if got.State != test.want {
+				t.Errorf("got %s (%s), want %s", got.State, got.Reason, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package vercel
+
+import (
+	"encoding/json"
+	"errors"
+	"hermes/app/httpapi"
+	"hermes/app/types"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultBaseURL = "https://api.vercel.com"
+
+const defaultTokenEnv = "VERCEL_TOKEN"
+
+const requestTimeout = 10 * time.Second
+
+var errNotFound = errors.New("vercel resource not found")
+
+// Client is a minimal Vercel REST client
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+	teamID     string
+}
+
+// TokenEnvVar returns the environment variable the token is read from
+func TokenEnvVar(definition *types.VercelDefinition) string {
+	if definition != nil && definition.TokenEnv != "" {
+		return definition.TokenEnv
+	}
+
+	return defaultTokenEnv
+}
+
+// NewClient builds a client from the vercel section of the config, which
+// may be nil
+func NewClient(definition *types.VercelDefinition) *Client {
+	client := &Client{
+		httpClient: &http.Client{Timeout: requestTimeout},
+		baseURL:    defaultBaseURL,
+		token:      os.Getenv(TokenEnvVar(definition)),
+	}
+
+	if definition != nil {
+		if definition.BaseURL != "" {
+			client.baseURL = strings.TrimSuffix(definition.BaseURL, "/")
+		}
+
+		client.teamID = definition.TeamID
+	}
+
+	return client
+}
+
+// get decodes the json response for path into out, returning errNotFound on
+// a 404. Requests are scoped to the configured team
+func (c *Client) get(path string, query url.Values, out any) error {
+	if c.teamID != "" {
+		query.Set("teamId", c.teamID)
+	}
+
+	requestURL := c.baseURL + path
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return httpapi.ResponseError("vercel", resp, errorMessage)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// errorMessage returns the message from vercel's error object
+func errorMessage(body []byte) string {
+	var response struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	if json.Unmarshal(body, &response) != nil {
+		return ""
+	}
+
+	return response.Error.Message
+}
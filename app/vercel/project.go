@@ -0,0 +1,152 @@
+package vercel
+
+import (
+	"errors"
+	"fmt"
+	"hermes/app/types"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var _ types.ResourceStatus = ProjectStatus{}
+
+type ProjectStatus struct {
+	InstanceExists bool   `json:"exists"`
+	ProjectID      string `json:"project_id"`
+	Framework      string `json:"framework"`
+	// the latest production deployment, empty when there are none
+	DeploymentID    string    `json:"deployment_id"`
+	DeploymentState string    `json:"status"`
+	DeploymentUrl   string    `json:"url"`
+	Branch          string    `json:"branch"`
+	CommitSHA       string    `json:"commit_sha"`
+	CreatedAt       time.Time `json:"created_at"`
+	// the newest ready production deployment, which the production domains
+	// stay aliased to until a later deployment is ready. Only looked up when
+	// the latest deployment isn't ready itself
+	ReadyDeploymentID string `json:"ready_deployment_id,omitempty"`
+}
+
+func (p ProjectStatus) IsResourceStatus() {}
+
+// GetHealth judges a project on its latest production deployment. Vercel
+// only promotes a deployment once it is ready, so while there is an earlier
+// ready one a building deployment is healthy and an errored or canceled one
+// is degraded
+func (p ProjectStatus) GetHealth() types.Health {
+	switch {
+	case !p.InstanceExists:
+		return types.Unhealthy("not found")
+	case p.DeploymentState == "ready":
+		return types.Healthy()
+	case p.DeploymentState == "":
+		return types.Unhealthy("no production deployments")
+	case p.ReadyDeploymentID == "":
+		return types.Unhealthy(fmt.Sprintf("no ready production deployment, latest is %s", p.DeploymentState))
+	case p.DeploymentState == "building", p.DeploymentState == "queued", p.DeploymentState == "initializing":
+		return types.Healthy()
+	default:
+		return types.Degraded(fmt.Sprintf("latest production deployment is %s", p.DeploymentState))
+	}
+}
+
+func (p ProjectStatus) Exists() bool {
+	return p.InstanceExists
+}
+
+func (p ProjectStatus) GetStatusString() string {
+	if p.DeploymentState == "" {
+		return "no-deployments"
+	}
+
+	return p.DeploymentState
+}
+
+type deploymentsResponse struct {
+	Deployments []struct {
+		UID   string `json:"uid"`
+		URL   string `json:"url"`
+		State string `json:"state"`
+		// milliseconds since the epoch
+		Created int64 `json:"created"`
+		Meta    struct {
+			GitHubCommitRef string `json:"githubCommitRef"`
+			GitHubCommitSHA string `json:"githubCommitSha"`
+		} `json:"meta"`
+	} `json:"deployments"`
+}
+
+// GetProjectStatus reads the latest production deployment of a project,
+// identified by its name or id
+func GetProjectStatus(client *Client, identifier string) (ProjectStatus, error) {
+	var project struct {
+		ID        string `json:"id"`
+		Framework string `json:"framework"`
+	}
+
+	err := client.get("/v9/projects/"+url.PathEscape(identifier), url.Values{}, &project)
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			return ProjectStatus{
+				InstanceExists: false,
+			}, nil
+		}
+
+		return ProjectStatus{}, err
+	}
+
+	var deployments deploymentsResponse
+	err = client.get(
+		"/v6/deployments",
+		url.Values{
+			"projectId": {project.ID},
+			"target":    {"production"},
+			"limit":     {"1"},
+		},
+		&deployments,
+	)
+	if err != nil {
+		return ProjectStatus{}, err
+	}
+
+	status := ProjectStatus{
+		InstanceExists: true,
+		ProjectID:      project.ID,
+		Framework:      project.Framework,
+	}
+
+	if len(deployments.Deployments) > 0 {
+		deployment := deployments.Deployments[0]
+
+		status.DeploymentID = deployment.UID
+		status.DeploymentState = strings.ToLower(deployment.State)
+		status.DeploymentUrl = "https://" + deployment.URL
+		status.Branch = deployment.Meta.GitHubCommitRef
+		status.CommitSHA = deployment.Meta.GitHubCommitSHA
+		status.CreatedAt = time.UnixMilli(deployment.Created)
+	}
+
+	if status.DeploymentState != "" && status.DeploymentState != "ready" {
+		var readyDeployments deploymentsResponse
+		err = client.get(
+			"/v6/deployments",
+			url.Values{
+				"projectId": {project.ID},
+				"target":    {"production"},
+				"state":     {"READY"},
+				"limit":     {"1"},
+			},
+			&readyDeployments,
+		)
+		if err != nil {
+			return ProjectStatus{}, err
+		}
+
+		if len(readyDeployments.Deployments) > 0 {
+			status.ReadyDeploymentID = readyDeployments.Deployments[0].UID
+		}
+	}
+
+	return status, nil
+}
@@ -0,0 +1,180 @@
+package vercel
+
+import (
+	"fmt"
+	"hermes/app/types"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestProjectStatusGetHealth(t *testing.T) {
+	tests := []struct {
+		name   string
+		status ProjectStatus
+		want   types.HealthState
+	}{
+		{"not found", ProjectStatus{}, types.HealthUnhealthy},
+		{"ready", ProjectStatus{InstanceExists: true, DeploymentState: "ready"}, types.HealthHealthy},
+		{"no deployments", ProjectStatus{InstanceExists: true}, types.HealthUnhealthy},
+		{
+			"first deployment building",
+			ProjectStatus{InstanceExists: true, DeploymentState: "building"},
+			types.HealthUnhealthy,
+		},
+		{
+			"building over a ready deployment",
+			ProjectStatus{InstanceExists: true, DeploymentState: "building", ReadyDeploymentID: "abc"},
+			types.HealthHealthy,
+		},
+		{
+			"failed over a ready deployment",
+			ProjectStatus{InstanceExists: true, DeploymentState: "error", ReadyDeploymentID: "abc"},
+			types.HealthDegraded,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.status.GetHealth()
+			if got.State != test.want {
+				t.Errorf("got %s (%s), want %s", got.State, got.Reason, test.want)
+			}
+		})
+	}
+}
+
+func newStubVercel(t *testing.T, latestState string) *Client {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v9/projects/{name}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" || r.URL.Query().Get("teamId") != "team_1" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"error": {"code": "forbidden", "message": "Not authorized"}}`)
+			return
+		}
+
+		switch r.PathValue("name") {
+		case "shop":
+			fmt.Fprint(w, `{"id": "prj_1", "framework": "nextjs"}`)
+		case "flaky":
+			w.WriteHeader(http.StatusBadGateway)
+			fmt.Fprint(w, "<html>bad gateway</html>")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": {"code": "not_found", "message": "Project not found"}}`)
+		}
+	})
+	mux.HandleFunc("/v6/deployments", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("projectId") != "prj_1" || query.Get("target") != "production" {
+			http.Error(w, "unexpected query", http.StatusBadRequest)
+			return
+		}
+
+		if query.Get("state") == "READY" {
+			fmt.Fprint(w, `{"deployments": [{"uid": "dpl_ready", "url": "shop-ready.vercel.app", "state": "READY", "created": 1700000000000}]}`)
+			return
+		}
+
+		fmt.Fprintf(w, `{"deployments": [{
+			"uid": "dpl_latest",
+			"url": "shop-latest.vercel.app",
+			"state": %q,
+			"created": 1700000600000,
+			"meta": {"githubCommitRef": "main", "githubCommitSha": "abc123"}
+		}]}`, latestState)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	t.Setenv("HERMES_TEST_VERCEL_TOKEN", "token")
+
+	return NewClient(&types.VercelDefinition{
+		BaseURL:  server.URL,
+		TokenEnv: "HERMES_TEST_VERCEL_TOKEN",
+		TeamID:   "team_1",
+	})
+}
+
+func TestGetProjectStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		latestState string
+		want        ProjectStatus
+		wantHealth  types.HealthState
+	}{
+		{
+			name:        "latest deployment ready",
+			latestState: "READY",
+			want: ProjectStatus{
+				DeploymentState: "ready",
+			},
+			wantHealth: types.HealthHealthy,
+		},
+		{
+			name:        "latest deployment failed",
+			latestState: "ERROR",
+			want: ProjectStatus{
+				DeploymentState:   "error",
+				ReadyDeploymentID: "dpl_ready",
+			},
+			wantHealth: types.HealthDegraded,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := newStubVercel(t, test.latestState)
+
+			status, err := GetProjectStatus(client, "shop")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := test.want
+			want.InstanceExists = true
+			want.ProjectID = "prj_1"
+			want.Framework = "nextjs"
+			want.DeploymentID = "dpl_latest"
+			want.DeploymentUrl = "https://shop-latest.vercel.app"
+			want.Branch = "main"
+			want.CommitSHA = "abc123"
+			want.CreatedAt = time.UnixMilli(1700000600000)
+
+			if !reflect.DeepEqual(status, want) {
+				t.Errorf("got %+v, want %+v", status, want)
+			}
+
+			if health := status.GetHealth(); health.State != test.wantHealth {
+				t.Errorf("got health %s (%s), want %s", health.State, health.Reason, test.wantHealth)
+			}
+		})
+	}
+}
+
+func TestGetProjectStatusErrors(t *testing.T) {
+	client := newStubVercel(t, "READY")
+
+	status, err := GetProjectStatus(client, "missing")
+	if err != nil || status.InstanceExists {
+		t.Errorf("got (%+v, %v), want a missing project", status, err)
+	}
+
+	_, err = GetProjectStatus(client, "flaky")
+	want := "vercel api returned 502: <html>bad gateway</html>"
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+
+	client.token = "wrong"
+	_, err = GetProjectStatus(client, "shop")
+	want = "vercel api returned 403: Not authorized"
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+}